@@ -1,91 +1,414 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"hash/fnv"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/BarneyRubble12/specdrill/internal/core/domain"
+	"github.com/BarneyRubble12/specdrill/internal/core/executor"
+	"github.com/BarneyRubble12/specdrill/internal/core/fuzzer"
+	"github.com/BarneyRubble12/specdrill/internal/core/generator"
+	"github.com/BarneyRubble12/specdrill/internal/core/model"
+	"github.com/BarneyRubble12/specdrill/internal/core/parser"
 	"github.com/BarneyRubble12/specdrill/internal/di"
 )
 
+// defaultTimeout bounds each request when --timeout is not given, so a hung
+// endpoint fails its case rather than stalling the run
+const defaultTimeout = 30 * time.Second
+
+// defaultFuzzTimeout is the shorter request timeout for --fuzz, where a
+// hanging endpoint is a finding
+const defaultFuzzTimeout = 10 * time.Second
+
 func main() {
 	// Parse command line flags
 	specPath := flag.String("spec", "", "Path to OpenAPI specification file (YAML/JSON) or URL")
 	baseURL := flag.String("base-url", "", "Base URL for the API (overrides server URL in spec)")
+	includeOptional := flag.Bool("include-optional", false, "Also send optional query, header and cookie parameters")
+	drift := flag.Bool("drift", false, "Report undocumented statuses, properties and enum values as drift warnings")
+	negative := flag.Bool("negative", false, "Also send invalid requests, each expected to be rejected with a 4xx")
+	boundary := flag.Bool("boundary", false, "Also send values at and just beyond each declared bound")
+	pairwise := flag.Bool("pairwise", false, "Also send combinations covering every pair of enum and boolean parameter values")
+	strength := flag.Int("strength", 2, "Number of parameters --pairwise combines, e.g. 3 for every triple")
+	maxCombinations := flag.Int("max-combinations", 0, "Most --pairwise cases per operation; 0 for no limit")
+	maxSize := flag.Int("max-size", generator.DefaultMaxSize, "Longest string or array --negative and --boundary cases send; bounds beyond it are not probed")
+	fuzz := flag.Bool("fuzz", false, "Send mutated requests instead of the test suite, flagging 5xx responses and timeouts")
+	seed := flag.Int64("seed", 0, "Seed for --fuzz (default: random, printed so the run can be replayed)")
+	iterations := flag.Int("iterations", 100, "Number of cases to send with --fuzz; 0 for no limit")
+	fuzzDuration := flag.Duration("fuzz-duration", 0, "Stop --fuzz after this long, e.g. 30s")
+	fuzzCase := flag.Int("fuzz-case", -1, "Replay only this case index of the --seed run")
+	casesPath := flag.String("cases", "", "JSON test case file, or directory of them, to run alongside the generated cases")
+	reproDir := flag.String("repro-dir", "repro", "Directory to save minimised reproductions of 5xx responses to")
+	repros := flag.Int("repros", 5, "Most 5xx responses to minimise, which takes extra requests, and save as reproductions; 0 for none")
+	workers := flag.Int("workers", 1, "Number of test cases to run at once")
+	maxPerHost := flag.Int("max-per-host", 0, "Most requests in flight to any one host; 0 for no cap")
+	rate := flag.Float64("rate", 0, "Most requests per second across the run; 0 for no limit")
+	operationRate := flag.Float64("operation-rate", 0, "Most requests per second to each operation; 0 for no limit")
+	retryBudget := flag.Duration("retry-budget", 30*time.Second, "Longest a case may wait on Retry-After from 429 and 503 responses; 0 to not retry them")
+	retries := flag.Int("retries", 0, "Times to retry a case after a connection error or a --retry-statuses response")
+	retryStatuses := flag.String("retry-statuses", "502,503,504", "Comma-separated status codes --retries applies to")
+	retryDelay := flag.Duration("retry-delay", 200*time.Millisecond, "Backoff before the first retry, doubling after each; jittered")
+	retryMaxDelay := flag.Duration("retry-max-delay", 5*time.Second, "Longest backoff between retries")
+	retryAllMethods := flag.Bool("retry-all-methods", false, "Also retry POST and PATCH, which are not idempotent")
+	secretsPath := flag.String("secrets", "", "JSON file of credentials by security scheme name; SPECDRILL_<SCHEME>_<FIELD> environment variables override it")
+	timeout := flag.Duration("timeout", defaultTimeout, "Timeout for each request, and for fetching a remote spec; 0 for none (default with --fuzz: 10s)")
+	runTimeout := flag.Duration("run-timeout", 0, "Stop the whole run after this long, reporting what completed; 0 for no limit")
 	flag.Parse()
 
 	// Validate required flags
 	if *specPath == "" {
 		fmt.Println("Error: --spec flag is required")
-		fmt.Println("Usage: specdrill --spec <file-path-or-url> [--base-url <api-base-url>]")
+		fmt.Println("Usage: specdrill --spec <file-path-or-url> [--base-url <api-base-url>] [--include-optional] [--drift] [--negative] [--boundary] [--max-size <n>] [--pairwise [--strength <t>] [--max-combinations <n>]] [--fuzz [--seed <n>] [--iterations <n>] [--fuzz-duration <d>] [--fuzz-case <i>]] [--cases <file-or-dir>] [--repro-dir <dir>] [--repros <n>] [--workers <n>] [--max-per-host <n>] [--rate <rps>] [--operation-rate <rps>] [--retry-budget <d>] [--retries <n> [--retry-statuses <codes>] [--retry-delay <d>] [--retry-max-delay <d>] [--retry-all-methods]] [--secrets <file>] [--timeout <d>] [--run-timeout <d>]")
 		fmt.Println("\nExamples:")
 		fmt.Println("  specdrill --spec ./openapi.yaml")
 		fmt.Println("  specdrill --spec https://api.example.com/openapi.json")
 		fmt.Println("  specdrill --spec ./openapi.yaml --base-url https://staging-api.example.com")
+		fmt.Println("  specdrill --spec ./openapi.yaml --fuzz --seed 42 --iterations 500")
 		flag.Usage()
 		os.Exit(1)
 	}
 
+	if *fuzz && !flagSet("timeout") {
+		*timeout = defaultFuzzTimeout
+	}
+
+	statuses, err := parseStatuses(*retryStatuses)
+	if err != nil {
+		fmt.Printf("Error: invalid --retry-statuses: %v\n", err)
+		os.Exit(1)
+	}
+
+	var credentials map[string]executor.Credential
+	if *secretsPath != "" {
+		credentials, err = executor.LoadCredentials(*secretsPath)
+		if err != nil {
+			fmt.Printf("Error loading secrets: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Ctrl-C stops dispatching new cases and cancels those in flight; the
+	// partial results are still reported. A second Ctrl-C exits at once.
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-sigCtx.Done()
+		stop()
+	}()
+	ctx := sigCtx
+	if *runTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(sigCtx, *runTimeout)
+		defer cancel()
+	}
+
 	// Initialize the application container
-	container, err := di.InitializeContainer()
+	container, err := di.InitializeContainer(parser.Options{
+		Timeout: *timeout,
+	}, generator.Options{
+		IncludeOptional: *includeOptional,
+		Negative:        *negative,
+		Boundary:        *boundary,
+		Combinations:    *pairwise,
+		Strength:        *strength,
+		MaxCombinations: *maxCombinations,
+		MaxSize:         *maxSize,
+	}, executor.Options{
+		Drift:              *drift,
+		Timeout:            *timeout,
+		Workers:            *workers,
+		MaxPerHost:         *maxPerHost,
+		RateLimit:          *rate,
+		OperationRateLimit: *operationRate,
+		RetryBudget:        *retryBudget,
+		Retry: executor.RetryPolicy{
+			MaxAttempts: *retries + 1,
+			Statuses:    statuses,
+			BaseDelay:   *retryDelay,
+			MaxDelay:    *retryMaxDelay,
+			AllMethods:  *retryAllMethods,
+		},
+		Credentials: credentials,
+	})
 	if err != nil {
 		fmt.Printf("Error initializing application: %v\n", err)
 		os.Exit(1)
 	}
 
 	// Parse the OpenAPI spec
-	spec, err := container.Parser.ParseSpec(*specPath, *baseURL)
+	spec, err := container.Parser.ParseSpec(ctx, *specPath, *baseURL)
 	if err != nil {
 		fmt.Printf("Error parsing spec: %v\n", err)
 		os.Exit(1)
 	}
 
-	totalTests := 0
-	passedTests := 0
-	failedTests := 0
+	for _, warning := range spec.Warnings {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+
+	if *fuzz {
+		options := fuzzer.Options{
+			Seed:       *seed,
+			Iterations: *iterations,
+			Duration:   *fuzzDuration,
+		}
+		if !flagSet("seed") {
+			options.Seed = time.Now().UnixNano()
+		}
+		if *fuzzCase >= 0 {
+			options.Cases = []int{*fuzzCase}
+		}
+		if runFuzz(ctx, container.Fuzzer, spec, options, *reproDir, *repros) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Generate the suite, then add any hand-written or saved cases
+	suite := container.Generator.GenerateSuite(spec)
+	if *casesPath != "" {
+		imported, err := container.Parser.ParseTestCases(*casesPath)
+		if err != nil {
+			fmt.Printf("Error loading test cases: %v\n", err)
+			os.Exit(1)
+		}
+		suite.TestCases = append(suite.TestCases, imported...)
+	}
 
 	fmt.Printf("\nTest Results for API (Base URL: %s)\n", spec.BaseURL)
 
-	for path, pathItem := range spec.Paths {
-		endpoints := []struct {
-			method string
-			op     interface{}
-		}{
-			{"GET", pathItem.Get},
-			{"POST", pathItem.Post},
-			{"PUT", pathItem.Put},
-			{"DELETE", pathItem.Delete},
-			{"PATCH", pathItem.Patch},
-			{"OPTIONS", pathItem.Options},
-			{"HEAD", pathItem.Head},
-		}
-		for _, ep := range endpoints {
-			if ep.op == nil {
-				continue
-			}
-			totalTests++
-			result, err := container.Executor.ExecuteTest(spec, path, ep.method)
-			if err != nil {
-				failedTests++
-				fmt.Printf("✗ %s %s\n  Error: %v\n", ep.method, path, err)
+	summary := container.Executor.RunSuite(ctx, spec, suite)
+	// Taken before any shrinking, whose requests would be recorded too
+	drifted := container.Executor.DriftSummary()
+	var acceptedInvalid []model.TestResult
+	shrunk := 0
+	for _, result := range summary.Results {
+		if result.Error != nil {
+			fmt.Printf("✗ %s\n  Error: %v\n", result.TestCase.Name, result.Error)
+			printAttempts(result.Attempts)
+			continue
+		}
+		if result.TestCase.Negative && result.StatusCode >= 200 && result.StatusCode < 300 {
+			acceptedInvalid = append(acceptedInvalid, result)
+		}
+		printResult(result)
+		if result.StatusCode >= 500 {
+			if shrunk >= *repros {
+				fmt.Printf("  Not minimised: --repros limit of %d reached\n", *repros)
 				continue
 			}
-			if result.StatusCode >= 200 && result.StatusCode < 300 {
-				passedTests++
-				fmt.Printf("✓ %s %s (%d)\n", ep.method, path, result.StatusCode)
-			} else {
-				failedTests++
-				fmt.Printf("✗ %s %s (%d)\n  Response: %s\n", ep.method, path, result.StatusCode, result.Body)
+			shrunk++
+			reproduce(ctx, container.Fuzzer, spec, result.TestCase, *reproDir, slug(result.TestCase.Name))
+		}
+	}
+
+	if len(acceptedInvalid) > 0 {
+		fmt.Printf("\nEndpoints Accepting Invalid Input: %d case(s)\n", len(acceptedInvalid))
+		for _, result := range acceptedInvalid {
+			fmt.Printf("  %s (%d)\n", result.TestCase.Name, result.StatusCode)
+		}
+	}
+
+	if len(suite.Coverage) > 0 {
+		fmt.Printf("\nCombination Coverage\n")
+		for _, covered := range suite.Coverage {
+			fmt.Printf("  %s %s: %d case(s) cover %d/%d %d-way combinations (%.1f%%), of %d exhaustive\n",
+				covered.Method, covered.Path, covered.Cases, covered.Covered, covered.Total, covered.Strength, covered.Percent(), covered.Exhaustive)
+		}
+	}
+
+	if *drift {
+		fmt.Printf("\nDrift Summary: %d operation(s) with undocumented behaviour\n", len(drifted))
+		for _, operation := range drifted {
+			fmt.Printf("  %s %s\n", operation.Method, operation.Path)
+			for _, warning := range operation.Warnings {
+				fmt.Printf("    %dx %s\n", warning.Count, warning.Message)
 			}
 		}
 	}
 
-	fmt.Printf("\nTotal Tests: %d\n", totalTests)
-	fmt.Printf("Passed: %d\n", passedTests)
-	fmt.Printf("Failed: %d\n", failedTests)
+	fmt.Printf("\nTotal Tests: %d\n", summary.TotalTests)
+	fmt.Printf("Passed: %d\n", summary.PassedTests)
+	fmt.Printf("Failed: %d\n", summary.FailedTests)
+	fmt.Printf("Duration: %dms\n", summary.Duration)
+	if summary.Interrupted {
+		fmt.Printf("Interrupted: %d case(s) not run\n", summary.Skipped)
+	}
 
-	// Exit with non-zero status if any tests failed
-	if failedTests > 0 {
+	// Exit with non-zero status if any tests failed or the run was cut short
+	if summary.FailedTests > 0 || summary.Interrupted {
 		os.Exit(1)
 	}
 }
+
+// runFuzz sends fuzz cases and reports each failure with the flags that
+// replay it, minimising at most repros of them. It returns the number of
+// failures.
+func runFuzz(ctx context.Context, f *fuzzer.Fuzzer, spec *domain.APISpec, options fuzzer.Options, reproDir string, repros int) int {
+	fmt.Printf("\nFuzzing API (Base URL: %s) with seed %d\n", spec.BaseURL, options.Seed)
+
+	report := f.Run(ctx, spec, options)
+	shrunk := 0
+	for _, finding := range report.Findings {
+		if finding.Err != nil {
+			fmt.Printf("✗ %s\n  Timed out: %v\n", finding.Case.Name, finding.Err)
+		} else {
+			fmt.Printf("✗ %s (%d)\n", finding.Case.Name, finding.StatusCode)
+		}
+		fmt.Printf("  Seed: %d, case: %d\n  Replay: --fuzz --seed %d --fuzz-case %d\n",
+			finding.Seed, finding.Index, finding.Seed, finding.Index)
+		if finding.StatusCode == 0 {
+			continue
+		}
+		if shrunk >= repros {
+			fmt.Printf("  Not minimised: --repros limit of %d reached\n", repros)
+			continue
+		}
+		shrunk++
+		reproduce(ctx, f, spec, finding.Case, reproDir, fmt.Sprintf("fuzz-%d-%d", finding.Seed, finding.Index))
+	}
+
+	fmt.Printf("\nSeed: %d\n", report.Seed)
+	fmt.Printf("Cases: %d\n", report.Cases)
+	fmt.Printf("Failures: %d\n", len(report.Findings))
+	if report.Interrupted {
+		fmt.Printf("Interrupted after %d case(s)\n", report.Cases)
+	}
+	return len(report.Findings)
+}
+
+// reproduce shrinks a case that caused a 5xx and prints the minimal request
+// as a curl command, saving it as a JSON test case in dir
+func reproduce(ctx context.Context, f *fuzzer.Fuzzer, spec *domain.APISpec, testCase model.TestCase, dir, name string) {
+	if ctx.Err() != nil {
+		return
+	}
+	minimal, result, err := f.Shrink(ctx, spec, testCase)
+	if err != nil {
+		fmt.Printf("  Could not minimise: %v\n", err)
+		return
+	}
+	fmt.Printf("  Minimal reproduction (%d):\n    %s\n", result.StatusCode, result.Request.Curl())
+
+	data, err := json.MarshalIndent(minimal, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(dir, 0o755)
+	}
+	file := filepath.Join(dir, name+".json")
+	if err == nil {
+		err = os.WriteFile(file, append(data, '\n'), 0o644)
+	}
+	if err != nil {
+		fmt.Printf("  Could not save reproduction: %v\n", err)
+		return
+	}
+	fmt.Printf("  Saved: %s\n", file)
+}
+
+// slug turns a test case name into a file name. A hash of the full name
+// keeps names that differ only in punctuation, such as "GET /a/{id}" and
+// "GET /a/id", from overwriting each other's files.
+func slug(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "_"):
+			b.WriteByte('_')
+		}
+	}
+	s := strings.TrimSuffix(b.String(), "_")
+	if len(s) > 80 {
+		s = s[:80]
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(name))
+	return fmt.Sprintf("%s-%08x", s, hash.Sum32())
+}
+
+// parseStatuses parses a comma-separated list of status codes
+func parseStatuses(value string) ([]int, error) {
+	var statuses []int
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		status, err := strconv.Atoi(field)
+		if err != nil || status < 100 || status > 599 {
+			return nil, fmt.Errorf("%q is not a status code", field)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// flagSet reports whether a flag was given on the command line
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// printResult reports the outcome of a single test case
+func printResult(result model.TestResult) {
+	if result.Passed {
+		fmt.Printf("✓ %s (%d, %dms)\n", result.TestCase.Name, result.StatusCode, result.Duration)
+	} else {
+		received := "undocumented"
+		if result.MatchedStatus != "" {
+			received = "documented as " + result.MatchedStatus
+		}
+		fmt.Printf("✗ %s (%d, %dms)\n  Expected: %s, received %d (%s)\n",
+			result.TestCase.Name, result.StatusCode, result.Duration, strings.Join(result.TestCase.ExpectedStatuses, " or "), result.StatusCode, received)
+		if result.TestCase.Negative && result.StatusCode >= 200 && result.StatusCode < 300 {
+			fmt.Printf("  Invalid input was accepted\n")
+		}
+		for _, violation := range result.Violations {
+			fmt.Printf("  Violation: %s\n", violation)
+		}
+	}
+	printAttempts(result.Attempts)
+	for _, warning := range result.Drift {
+		fmt.Printf("  Drift: %s\n", warning)
+	}
+	if !result.Passed {
+		fmt.Printf("  Response: %s\n", result.Body)
+	}
+}
+
+// printAttempts lists each try of a case that was retried, so flaky
+// endpoints are visible even when the final attempt passed
+func printAttempts(attempts []model.Attempt) {
+	if len(attempts) < 2 {
+		return
+	}
+	fmt.Printf("  Attempts: %d\n", len(attempts))
+	for i, attempt := range attempts {
+		outcome := strconv.Itoa(attempt.StatusCode)
+		if attempt.Error != "" {
+			outcome = attempt.Error
+		}
+		fmt.Printf("    #%d %s (%dms)", i+1, outcome, attempt.Duration)
+		if attempt.Wait > 0 {
+			fmt.Printf(", waited %dms", attempt.Wait)
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,102 @@
+package domain
+
+import "strings"
+
+// Methods lists the HTTP methods a PathItem can hold, in the order they are
+// tested
+var Methods = []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS", "HEAD"}
+
+// Operation returns the operation for an HTTP method, or nil if the path
+// does not define one
+func (p PathItem) Operation(method string) *Operation {
+	switch strings.ToUpper(method) {
+	case "GET":
+		return p.Get
+	case "POST":
+		return p.Post
+	case "PUT":
+		return p.Put
+	case "DELETE":
+		return p.Delete
+	case "PATCH":
+		return p.Patch
+	case "OPTIONS":
+		return p.Options
+	case "HEAD":
+		return p.Head
+	}
+	return nil
+}
+
+// SerializationStyle returns the parameter's style, defaulting to simple for
+// path and header parameters and form for query and cookie parameters
+func (p Parameter) SerializationStyle() string {
+	if p.Style != "" {
+		return p.Style
+	}
+	if p.In == "query" || p.In == "cookie" {
+		return "form"
+	}
+	return "simple"
+}
+
+// Exploded reports whether arrays and objects are sent as separate
+// parameters. It defaults to true for the form style only.
+func (p Parameter) Exploded() bool {
+	if p.Explode != nil {
+		return *p.Explode
+	}
+	return p.SerializationStyle() == "form"
+}
+
+// ParametersFor returns the parameters that apply to an operation on this
+// path: the path-level ones, overridden by operation-level parameters with
+// the same name and location
+func (p PathItem) ParametersFor(operation *Operation) []Parameter {
+	var opParams []Parameter
+	if operation != nil {
+		opParams = operation.Parameters
+	}
+
+	params := make([]Parameter, 0, len(p.Parameters)+len(opParams))
+	for _, param := range p.Parameters {
+		overridden := false
+		for _, opParam := range opParams {
+			if opParam.Name == param.Name && opParam.In == param.In {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			params = append(params, param)
+		}
+	}
+	return append(params, opParams...)
+}
+
+// SecurityFor returns the alternative security requirements of an
+// operation: its own when declared, otherwise the spec's. Satisfying any one
+// of them is enough.
+func (s *APISpec) SecurityFor(operation *Operation) []SecurityRequirement {
+	if operation != nil && operation.Security != nil {
+		return operation.Security
+	}
+	return s.Security
+}
+
+// SerializationStyle returns the property's style, defaulting to form
+func (e Encoding) SerializationStyle() string {
+	if e.Style != "" {
+		return e.Style
+	}
+	return "form"
+}
+
+// Exploded reports whether array items are sent as separate fields. It
+// defaults to true for the form style only.
+func (e Encoding) Exploded() bool {
+	if e.Explode != nil {
+		return *e.Explode
+	}
+	return e.SerializationStyle() == "form"
+}
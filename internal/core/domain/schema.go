@@ -0,0 +1,342 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// UnmarshalJSON accepts both OpenAPI 3.0 and 3.1 (JSON Schema 2020-12)
+// schemas: boolean schemas, a type given as a string or an array, a const
+// that may legitimately be null, and exclusive bounds given either as 3.0
+// booleans or as 3.1 numbers.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	switch string(bytes.TrimSpace(data)) {
+	case "true":
+		*s = Schema{}
+		return nil
+	case "false":
+		*s = Schema{False: true}
+		return nil
+	}
+
+	type schemaAlias Schema
+	aux := struct {
+		*schemaAlias
+		Type             json.RawMessage `json:"type,omitempty"`
+		Const            json.RawMessage `json:"const,omitempty"`
+		ExclusiveMinimum json.RawMessage `json:"exclusiveMinimum,omitempty"`
+		ExclusiveMaximum json.RawMessage `json:"exclusiveMaximum,omitempty"`
+	}{schemaAlias: (*schemaAlias)(s)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	var err error
+	if s.ExclusiveMinimum, err = exclusiveBound(aux.ExclusiveMinimum, &s.Minimum, func(a, b float64) bool { return a > b }); err != nil {
+		return fmt.Errorf("invalid exclusiveMinimum: %w", err)
+	}
+	if s.ExclusiveMaximum, err = exclusiveBound(aux.ExclusiveMaximum, &s.Maximum, func(a, b float64) bool { return a < b }); err != nil {
+		return fmt.Errorf("invalid exclusiveMaximum: %w", err)
+	}
+
+	s.Types = nil
+	if len(aux.Type) > 0 {
+		var single string
+		if err := json.Unmarshal(aux.Type, &single); err == nil {
+			s.Types = []string{single}
+		} else if err := json.Unmarshal(aux.Type, &s.Types); err != nil {
+			return fmt.Errorf("schema type must be a string or an array of strings: %w", err)
+		}
+	}
+	s.Type = ""
+	for _, t := range s.Types {
+		if t != "null" {
+			s.Type = t
+			break
+		}
+	}
+	if s.Type == "" && len(s.Types) > 0 {
+		s.Type = s.Types[0]
+	}
+
+	s.HasConst = len(aux.Const) > 0
+	s.Const = nil
+	if s.HasConst {
+		if err := json.Unmarshal(aux.Const, &s.Const); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exclusiveBound decodes an exclusive bound. A 3.1 numeric bound replaces
+// the inclusive one when it is at least as tight.
+func exclusiveBound(raw json.RawMessage, bound **float64, tighter func(a, b float64) bool) (bool, error) {
+	if len(raw) == 0 {
+		return false, nil
+	}
+
+	var flag bool
+	if err := json.Unmarshal(raw, &flag); err == nil {
+		return flag, nil
+	}
+
+	var value float64
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return false, err
+	}
+	if *bound == nil || **bound == value || tighter(value, **bound) {
+		*bound = &value
+		return true, nil
+	}
+	return false, nil
+}
+
+// MarshalJSON writes Types back out as a string or an array as appropriate
+func (s Schema) MarshalJSON() ([]byte, error) {
+	if s.False {
+		return []byte("false"), nil
+	}
+
+	type schemaAlias Schema
+	aux := struct {
+		schemaAlias
+		Type  interface{} `json:"type,omitempty"`
+		Const interface{} `json:"const,omitempty"`
+	}{schemaAlias: schemaAlias(s)}
+
+	switch len(s.Types) {
+	case 0:
+		if s.Type != "" {
+			aux.Type = s.Type
+		}
+	case 1:
+		aux.Type = s.Types[0]
+	default:
+		aux.Type = s.Types
+	}
+
+	aux.Const = s.Const
+
+	data, err := json.Marshal(aux)
+	if err != nil || !s.HasConst || s.Const != nil {
+		return data, err
+	}
+
+	// encoding/json drops a null const under omitempty, so add it by hand
+	if string(data) == "{}" {
+		return []byte(`{"const":null}`), nil
+	}
+	return append(data[:len(data)-1], []byte(`,"const":null}`)...), nil
+}
+
+// AllowsType reports whether the schema declares t among its types. A
+// schema without a type allows everything.
+func (s Schema) AllowsType(t string) bool {
+	if len(s.Types) == 0 {
+		return s.Type == "" || s.Type == t
+	}
+	for _, declared := range s.Types {
+		if declared == t {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsNull reports whether null is an explicitly permitted value, either
+// through 3.0's nullable or a "null" entry in a 3.1 type array
+func (s Schema) AllowsNull() bool {
+	if s.Nullable {
+		return true
+	}
+	for _, declared := range s.Types {
+		if declared == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRecursive reports whether the schema is a reference back into a
+// structure that is still being expanded, with no content of its own
+func (s Schema) IsRecursive() bool {
+	return s.Ref != "" && reflect.DeepEqual(s, Schema{Ref: s.Ref})
+}
+
+// MergeAllOf flattens allOf into a single schema describing a value that
+// satisfies every member: properties and required fields are combined and
+// the first declared type, format and items win.
+func (s Schema) MergeAllOf() Schema {
+	if len(s.AllOf) == 0 {
+		return s
+	}
+
+	merged := s
+	merged.AllOf = nil
+	for _, member := range s.AllOf {
+		merged = mergeSchemas(merged, member.MergeAllOf())
+	}
+	return merged
+}
+
+func mergeSchemas(a, b Schema) Schema {
+	if a.Type == "" {
+		a.Type = b.Type
+	}
+	if len(a.Types) == 0 {
+		a.Types = b.Types
+	}
+	if a.Format == "" {
+		a.Format = b.Format
+	}
+	if a.Items == nil {
+		a.Items = b.Items
+	}
+	if len(a.Enum) == 0 {
+		a.Enum = b.Enum
+	}
+	if !a.HasConst && b.HasConst {
+		a.Const, a.HasConst = b.Const, true
+	}
+	if a.Default == nil {
+		a.Default = b.Default
+	}
+	if a.Example == nil {
+		a.Example = b.Example
+	}
+	if a.Discriminator == nil {
+		a.Discriminator = b.Discriminator
+	}
+	if a.Not == nil {
+		a.Not = b.Not
+	}
+	if a.Pattern == "" {
+		a.Pattern = b.Pattern
+	}
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = b.AdditionalProperties
+	}
+	if a.MultipleOf == nil {
+		a.MultipleOf = b.MultipleOf
+	}
+	if b.Minimum != nil && (a.Minimum == nil || *b.Minimum > *a.Minimum || *b.Minimum == *a.Minimum && b.ExclusiveMinimum) {
+		a.Minimum, a.ExclusiveMinimum = b.Minimum, b.ExclusiveMinimum
+	}
+	if b.Maximum != nil && (a.Maximum == nil || *b.Maximum < *a.Maximum || *b.Maximum == *a.Maximum && b.ExclusiveMaximum) {
+		a.Maximum, a.ExclusiveMaximum = b.Maximum, b.ExclusiveMaximum
+	}
+	a.MinLength = tighterInt(a.MinLength, b.MinLength, true)
+	a.MaxLength = tighterInt(a.MaxLength, b.MaxLength, false)
+	a.MinItems = tighterInt(a.MinItems, b.MinItems, true)
+	a.MaxItems = tighterInt(a.MaxItems, b.MaxItems, false)
+	a.UniqueItems = a.UniqueItems || b.UniqueItems
+	a.Nullable = a.Nullable && b.Nullable
+	a.ReadOnly = a.ReadOnly || b.ReadOnly
+	a.WriteOnly = a.WriteOnly || b.WriteOnly
+	a.Deprecated = a.Deprecated || b.Deprecated
+	a.OneOf = append(a.OneOf, b.OneOf...)
+	a.AnyOf = append(a.AnyOf, b.AnyOf...)
+	a.False = a.False || b.False
+
+	if len(b.Properties) > 0 {
+		properties := make(map[string]Schema, len(a.Properties)+len(b.Properties))
+		for name, prop := range a.Properties {
+			properties[name] = prop
+		}
+		for name, prop := range b.Properties {
+			if existing, ok := properties[name]; ok {
+				prop = mergeSchemas(existing, prop)
+			}
+			properties[name] = prop
+		}
+		a.Properties = properties
+	}
+
+	for _, name := range b.Required {
+		if !containsString(a.Required, name) {
+			a.Required = append(a.Required, name)
+		}
+	}
+	return a
+}
+
+// tighterInt returns the stricter of two optional length or count limits
+func tighterInt(a, b *int, lower bool) *int {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case lower == (*b > *a):
+		return b
+	}
+	return a
+}
+
+// Alternatives returns the oneOf or anyOf members a value may match
+func (s Schema) Alternatives() []Schema {
+	if len(s.OneOf) > 0 {
+		return s.OneOf
+	}
+	return s.AnyOf
+}
+
+// Discriminate picks the alternative named by the discriminator property of
+// value. It reports false when the schema has no discriminator, the property
+// is missing, or no alternative matches its value.
+func (s Schema) Discriminate(value interface{}) (Schema, bool) {
+	if s.Discriminator == nil {
+		return Schema{}, false
+	}
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return Schema{}, false
+	}
+	name, ok := obj[s.Discriminator.PropertyName].(string)
+	if !ok {
+		return Schema{}, false
+	}
+
+	for _, alt := range s.Alternatives() {
+		if s.DiscriminatorValue(alt) == name {
+			return alt, true
+		}
+	}
+	return Schema{}, false
+}
+
+// DiscriminatorValue returns the discriminator property value that selects
+// alt: the explicit mapping entry pointing at it, or else the name of the
+// schema it references
+func (s Schema) DiscriminatorValue(alt Schema) string {
+	if s.Discriminator != nil {
+		// Several values may map to the same schema; pick one deterministically
+		mapped := ""
+		for value, target := range s.Discriminator.Mapping {
+			matches := target == alt.Ref || !strings.ContainsAny(target, "#/") && strings.HasSuffix(alt.Ref, "/"+target)
+			if matches && (mapped == "" || value < mapped) {
+				mapped = value
+			}
+		}
+		if mapped != "" {
+			return mapped
+		}
+	}
+	if i := strings.LastIndex(alt.Ref, "/"); i >= 0 {
+		return alt.Ref[i+1:]
+	}
+	return ""
+}
+
+func containsString(values []string, v string) bool {
+	for _, candidate := range values {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
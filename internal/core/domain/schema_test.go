@@ -0,0 +1,194 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		validate func(*testing.T, Schema)
+		output   string
+	}{
+		{
+			name:  "Single type",
+			input: `{"type": "string"}`,
+			validate: func(t *testing.T, s Schema) {
+				assert.Equal(t, "string", s.Type)
+				assert.Equal(t, []string{"string"}, s.Types)
+				assert.False(t, s.AllowsNull())
+			},
+			output: `{"type":"string"}`,
+		},
+		{
+			name:  "Type array with null",
+			input: `{"type": ["null", "integer"]}`,
+			validate: func(t *testing.T, s Schema) {
+				assert.Equal(t, "integer", s.Type)
+				assert.True(t, s.AllowsNull())
+				assert.True(t, s.AllowsType("integer"))
+				assert.False(t, s.AllowsType("string"))
+			},
+			output: `{"type":["null","integer"]}`,
+		},
+		{
+			name:  "Null const",
+			input: `{"const": null}`,
+			validate: func(t *testing.T, s Schema) {
+				assert.True(t, s.HasConst)
+				assert.Nil(t, s.Const)
+			},
+			output: `{"const":null}`,
+		},
+		{
+			name:  "Boolean schemas",
+			input: `{"items": false, "unevaluatedProperties": true}`,
+			validate: func(t *testing.T, s Schema) {
+				assert.True(t, s.Items.False)
+				assert.False(t, s.UnevaluatedProperties.False)
+			},
+			output: `{"items":false,"unevaluatedProperties":{}}`,
+		},
+		{
+			name:  "OpenAPI 3.0 exclusive bounds",
+			input: `{"type": "number", "minimum": 1, "exclusiveMinimum": true, "maximum": 10}`,
+			validate: func(t *testing.T, s Schema) {
+				assert.Equal(t, 1.0, *s.Minimum)
+				assert.True(t, s.ExclusiveMinimum)
+				assert.Equal(t, 10.0, *s.Maximum)
+				assert.False(t, s.ExclusiveMaximum)
+			},
+			output: `{"type":"number","minimum":1,"exclusiveMinimum":true,"maximum":10}`,
+		},
+		{
+			name:  "OpenAPI 3.1 numeric exclusive bounds",
+			input: `{"type": "integer", "minimum": 0, "exclusiveMinimum": 5, "exclusiveMaximum": 100}`,
+			validate: func(t *testing.T, s Schema) {
+				assert.Equal(t, 5.0, *s.Minimum)
+				assert.True(t, s.ExclusiveMinimum)
+				assert.Equal(t, 100.0, *s.Maximum)
+				assert.True(t, s.ExclusiveMaximum)
+			},
+			output: `{"type":"integer","minimum":5,"exclusiveMinimum":true,"maximum":100,"exclusiveMaximum":true}`,
+		},
+		{
+			name:  "String and array constraints",
+			input: `{"type": "array", "minItems": 1, "maxItems": 3, "uniqueItems": true, "nullable": true, "items": {"type": "string", "minLength": 2, "pattern": "^[a-z]+$"}}`,
+			validate: func(t *testing.T, s Schema) {
+				assert.Equal(t, 1, *s.MinItems)
+				assert.Equal(t, 3, *s.MaxItems)
+				assert.True(t, s.UniqueItems)
+				assert.True(t, s.AllowsNull())
+				assert.Equal(t, 2, *s.Items.MinLength)
+				assert.Nil(t, s.Items.MaxLength)
+				assert.Equal(t, "^[a-z]+$", s.Items.Pattern)
+			},
+			output: `{"type":"array","minItems":1,"maxItems":3,"uniqueItems":true,"nullable":true,"items":{"type":"string","minLength":2,"pattern":"^[a-z]+$"}}`,
+		},
+		{
+			name:  "Recursive reference",
+			input: `{"$ref": "#/components/schemas/Node"}`,
+			validate: func(t *testing.T, s Schema) {
+				assert.True(t, s.IsRecursive())
+			},
+			output: `{"$ref":"#/components/schemas/Node"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Schema
+			assert.NoError(t, json.Unmarshal([]byte(tt.input), &s))
+			tt.validate(t, s)
+
+			out, err := json.Marshal(s)
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.output, string(out))
+		})
+	}
+}
+
+func TestSchemaMergeAllOf(t *testing.T) {
+	s := Schema{
+		AllOf: []Schema{
+			{
+				Type:       "object",
+				Required:   []string{"id"},
+				Properties: map[string]Schema{"id": {Type: "integer"}},
+			},
+			{
+				Required:   []string{"name", "id"},
+				Properties: map[string]Schema{"name": {Type: "string"}},
+				AllOf: []Schema{
+					{Properties: map[string]Schema{"tag": {Type: "string"}}},
+				},
+			},
+		},
+	}
+
+	merged := s.MergeAllOf()
+
+	assert.Equal(t, "object", merged.Type)
+	assert.Equal(t, []string{"id", "name"}, merged.Required)
+	assert.Len(t, merged.Properties, 3)
+	assert.Empty(t, merged.AllOf)
+}
+
+func TestSchemaMergeAllOfConstraints(t *testing.T) {
+	one, five, ten, twenty := 1.0, 5.0, 10.0, 20.0
+	two, eight := 2, 8
+	s := Schema{
+		AllOf: []Schema{
+			{Type: "integer", Minimum: &one, Maximum: &twenty, MaxLength: &eight},
+			{Minimum: &five, ExclusiveMinimum: true, Maximum: &ten, MinLength: &two},
+		},
+	}
+
+	merged := s.MergeAllOf()
+
+	assert.Equal(t, five, *merged.Minimum)
+	assert.True(t, merged.ExclusiveMinimum)
+	assert.Equal(t, ten, *merged.Maximum)
+	assert.Equal(t, two, *merged.MinLength)
+	assert.Equal(t, eight, *merged.MaxLength)
+}
+
+func TestSchemaDiscriminate(t *testing.T) {
+	cat := Schema{Ref: "#/components/schemas/Cat", Type: "object"}
+	dog := Schema{Ref: "#/components/schemas/Dog", Type: "object"}
+	s := Schema{
+		OneOf: []Schema{cat, dog},
+		Discriminator: &Discriminator{
+			PropertyName: "petType",
+			Mapping:      map[string]string{"doggo": "#/components/schemas/Dog"},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+		found bool
+	}{
+		{name: "Implicit mapping", value: map[string]interface{}{"petType": "Cat"}, want: cat.Ref, found: true},
+		{name: "Explicit mapping", value: map[string]interface{}{"petType": "doggo"}, want: dog.Ref, found: true},
+		{name: "Unknown value", value: map[string]interface{}{"petType": "Bird"}},
+		{name: "Missing property", value: map[string]interface{}{}},
+		{name: "Not an object", value: "Cat"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alt, ok := s.Discriminate(tt.value)
+			assert.Equal(t, tt.found, ok)
+			assert.Equal(t, tt.want, alt.Ref)
+		})
+	}
+
+	assert.Equal(t, "Cat", s.DiscriminatorValue(cat))
+	assert.Equal(t, "doggo", s.DiscriminatorValue(dog))
+}
@@ -1,13 +1,19 @@
 package domain
 
+import "github.com/getkin/kin-openapi/openapi3"
+
 // APISpec represents an OpenAPI specification
 type APISpec struct {
-	OpenAPI    string              `json:"openapi"`
-	Info       Info                `json:"info"`
-	Servers    []Server            `json:"servers,omitempty"`
-	Paths      map[string]PathItem `json:"paths"`
-	Components Components          `json:"components,omitempty"`
-	BaseURL    string              `json:"-"` // Not part of the spec, used for testing
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Servers    []Server              `json:"servers,omitempty"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Webhooks   map[string]PathItem   `json:"webhooks,omitempty"`
+	Components Components            `json:"components,omitempty"`
+	Security   []SecurityRequirement `json:"security,omitempty"` // Applies to operations without their own
+	BaseURL    string                `json:"-"`                  // Not part of the spec, used for testing
+	Document   *openapi3.T           `json:"-"`                  // The spec as loaded and validated by kin-openapi
+	Warnings   []string              `json:"-"`                  // Constructs that could not be loaded faithfully
 }
 
 // Info represents the API information
@@ -25,13 +31,14 @@ type Server struct {
 
 // PathItem represents a path in the API
 type PathItem struct {
-	Get     *Operation `json:"get,omitempty"`
-	Post    *Operation `json:"post,omitempty"`
-	Put     *Operation `json:"put,omitempty"`
-	Delete  *Operation `json:"delete,omitempty"`
-	Patch   *Operation `json:"patch,omitempty"`
-	Options *Operation `json:"options,omitempty"`
-	Head    *Operation `json:"head,omitempty"`
+	Parameters []Parameter `json:"parameters,omitempty"` // Shared by every operation on the path
+	Get        *Operation  `json:"get,omitempty"`
+	Post       *Operation  `json:"post,omitempty"`
+	Put        *Operation  `json:"put,omitempty"`
+	Delete     *Operation  `json:"delete,omitempty"`
+	Patch      *Operation  `json:"patch,omitempty"`
+	Options    *Operation  `json:"options,omitempty"`
+	Head       *Operation  `json:"head,omitempty"`
 }
 
 // Operation represents an API operation
@@ -42,15 +49,22 @@ type Operation struct {
 	RequestBody *RequestBody        `json:"requestBody,omitempty"`
 	Responses   map[string]Response `json:"responses"`
 	Tags        []string            `json:"tags,omitempty"`
+	// Security overrides the spec's requirements when set; an empty list
+	// means the operation needs no credentials
+	Security []SecurityRequirement `json:"security,omitempty"`
 }
 
 // Parameter represents an API parameter
 type Parameter struct {
-	Name        string `json:"name"`
-	In          string `json:"in"`
-	Description string `json:"description,omitempty"`
-	Required    bool   `json:"required,omitempty"`
-	Schema      Schema `json:"schema"`
+	Name        string             `json:"name"`
+	In          string             `json:"in"`
+	Description string             `json:"description,omitempty"`
+	Required    bool               `json:"required,omitempty"`
+	Schema      Schema             `json:"schema"`
+	Style       string             `json:"style,omitempty"`
+	Explode     *bool              `json:"explode,omitempty"`
+	Example     interface{}        `json:"example,omitempty"`
+	Examples    map[string]Example `json:"examples,omitempty"`
 }
 
 // RequestBody represents a request body
@@ -69,25 +83,83 @@ type Response struct {
 
 // MediaType represents a media type
 type MediaType struct {
-	Schema Schema `json:"schema"`
+	Schema   Schema              `json:"schema"`
+	Example  interface{}         `json:"example,omitempty"`
+	Examples map[string]Example  `json:"examples,omitempty"`
+	Encoding map[string]Encoding `json:"encoding,omitempty"` // Per property of a form body
+}
+
+// Encoding describes how a property of a form body is serialised
+type Encoding struct {
+	ContentType string `json:"contentType,omitempty"`
+	Style       string `json:"style,omitempty"`
+	Explode     *bool  `json:"explode,omitempty"`
+}
+
+// Example represents a named example value
+type Example struct {
+	Summary       string      `json:"summary,omitempty"`
+	Description   string      `json:"description,omitempty"`
+	Value         interface{} `json:"value,omitempty"`
+	ExternalValue string      `json:"externalValue,omitempty"`
 }
 
 // Header represents a response header
 type Header struct {
 	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
 	Schema      Schema `json:"schema"`
 }
 
-// Schema represents a JSON Schema
+// Schema represents a JSON Schema. References are inlined by the parser;
+// Ref keeps the original reference, and a schema with only Ref set marks
+// where a recursive structure refers back to itself.
 type Schema struct {
-	Type       string            `json:"type,omitempty"`
-	Format     string            `json:"format,omitempty"`
-	Properties map[string]Schema `json:"properties,omitempty"`
-	Items      *Schema           `json:"items,omitempty"`
-	Required   []string          `json:"required,omitempty"`
-	Enum       []interface{}     `json:"enum,omitempty"`
-	Default    interface{}       `json:"default,omitempty"`
-	Example    interface{}       `json:"example,omitempty"`
+	Ref                   string            `json:"$ref,omitempty"`
+	Type                  string            `json:"type,omitempty"` // First non-null entry of Types
+	Types                 []string          `json:"-"`              // All declared types, including "null"
+	Format                string            `json:"format,omitempty"`
+	Properties            map[string]Schema `json:"properties,omitempty"`
+	Items                 *Schema           `json:"items,omitempty"`
+	PrefixItems           []Schema          `json:"prefixItems,omitempty"`
+	Required              []string          `json:"required,omitempty"`
+	Enum                  []interface{}     `json:"enum,omitempty"`
+	Const                 interface{}       `json:"const,omitempty"`
+	HasConst              bool              `json:"-"` // Set when const is present, even if it is null
+	Default               interface{}       `json:"default,omitempty"`
+	Example               interface{}       `json:"example,omitempty"`
+	Examples              []interface{}     `json:"examples,omitempty"`
+	Defs                  map[string]Schema `json:"$defs,omitempty"`
+	UnevaluatedProperties *Schema           `json:"unevaluatedProperties,omitempty"`
+	AllOf                 []Schema          `json:"allOf,omitempty"`
+	OneOf                 []Schema          `json:"oneOf,omitempty"`
+	AnyOf                 []Schema          `json:"anyOf,omitempty"`
+	Not                   *Schema           `json:"not,omitempty"`
+	Discriminator         *Discriminator    `json:"discriminator,omitempty"`
+	Minimum               *float64          `json:"minimum,omitempty"`
+	Maximum               *float64          `json:"maximum,omitempty"`
+	ExclusiveMinimum      bool              `json:"exclusiveMinimum,omitempty"` // Minimum itself is excluded
+	ExclusiveMaximum      bool              `json:"exclusiveMaximum,omitempty"` // Maximum itself is excluded
+	MultipleOf            *float64          `json:"multipleOf,omitempty"`
+	MinLength             *int              `json:"minLength,omitempty"`
+	MaxLength             *int              `json:"maxLength,omitempty"`
+	Pattern               string            `json:"pattern,omitempty"`
+	MinItems              *int              `json:"minItems,omitempty"`
+	MaxItems              *int              `json:"maxItems,omitempty"`
+	UniqueItems           bool              `json:"uniqueItems,omitempty"`
+	AdditionalProperties  *Schema           `json:"additionalProperties,omitempty"`
+	Nullable              bool              `json:"nullable,omitempty"`
+	ReadOnly              bool              `json:"readOnly,omitempty"`
+	WriteOnly             bool              `json:"writeOnly,omitempty"`
+	Deprecated            bool              `json:"deprecated,omitempty"`
+	False                 bool              `json:"-"` // The boolean schema false, which no value satisfies
+}
+
+// Discriminator selects one of a schema's oneOf/anyOf alternatives based on
+// the value of a property
+type Discriminator struct {
+	PropertyName string            `json:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty"`
 }
 
 // Components represents reusable components
@@ -113,6 +185,10 @@ type SecurityScheme struct {
 	OpenIDConnectURL string      `json:"openIdConnectUrl,omitempty"`
 }
 
+// SecurityRequirement maps the names of security schemes that must all be
+// satisfied to the OAuth2 scopes each needs
+type SecurityRequirement map[string][]string
+
 // OAuthFlows represents OAuth flows
 type OAuthFlows struct {
 	Implicit          *OAuthFlow `json:"implicit,omitempty"`
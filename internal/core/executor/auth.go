@@ -0,0 +1,309 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/BarneyRubble12/specdrill/internal/core/domain"
+	"github.com/BarneyRubble12/specdrill/internal/core/logger"
+)
+
+// redacted replaces credentials in logged requests
+const redacted = "REDACTED"
+
+// Credential holds the secrets for one security scheme. Which fields are
+// needed depends on the scheme.
+type Credential struct {
+	APIKey       string `json:"apiKey,omitempty"`   // apiKey schemes
+	Username     string `json:"username,omitempty"` // HTTP basic and the OAuth2 password flow
+	Password     string `json:"password,omitempty"`
+	Token        string `json:"token,omitempty"` // HTTP bearer, or an access token to use instead of fetching one
+	ClientID     string `json:"clientId,omitempty"`
+	ClientSecret string `json:"clientSecret,omitempty"`
+}
+
+// LoadCredentials reads a secrets file: a JSON object mapping security
+// scheme names to their credentials
+func LoadCredentials(path string) (map[string]Credential, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var credentials map[string]Credential
+	if err := decoder.Decode(&credentials); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file %s: %w", path, err)
+	}
+	return credentials, nil
+}
+
+// credential returns the credential for a security scheme. Environment
+// variables named SPECDRILL_<SCHEME>_<FIELD>, e.g. SPECDRILL_PETSTORE_AUTH_CLIENT_ID,
+// override the secrets file field by field.
+func (e *Executor) credential(name string) Credential {
+	c := e.options.Credentials[name]
+	prefix := "SPECDRILL_" + envName(name) + "_"
+	for suffix, field := range map[string]*string{
+		"API_KEY":       &c.APIKey,
+		"USERNAME":      &c.Username,
+		"PASSWORD":      &c.Password,
+		"TOKEN":         &c.Token,
+		"CLIENT_ID":     &c.ClientID,
+		"CLIENT_SECRET": &c.ClientSecret,
+	} {
+		if value := os.Getenv(prefix + suffix); value != "" {
+			*field = value
+		}
+	}
+	return c
+}
+
+// envName upper-cases a scheme name and replaces anything but letters and
+// digits with underscores
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// secret is a credential placed on a request, kept out of the log
+type secret struct {
+	in    string
+	name  string
+	value string
+}
+
+// authorize adds the credentials for the first of the operation's security
+// requirements that every scheme has credentials for. When none can be
+// satisfied the request goes out without credentials, so the API's answer
+// is reported as is.
+func (e *Executor) authorize(ctx context.Context, spec *domain.APISpec, operation *domain.Operation, req *http.Request) ([]secret, error) {
+	for _, requirement := range spec.SecurityFor(operation) {
+		if len(requirement) == 0 {
+			// Anonymous access is allowed, but prefer an alternative we can
+			// authenticate with
+			continue
+		}
+
+		names := make([]string, 0, len(requirement))
+		credentials := make(map[string]Credential, len(requirement))
+		for name := range requirement {
+			scheme, ok := spec.Components.SecuritySchemes[name]
+			credential := e.credential(name)
+			if !ok || !satisfies(scheme, credential) {
+				names = nil
+				break
+			}
+			names = append(names, name)
+			credentials[name] = credential
+		}
+		if names == nil {
+			continue
+		}
+		sort.Strings(names)
+
+		var secrets []secret
+		for _, name := range names {
+			s, err := e.applyScheme(ctx, spec, spec.Components.SecuritySchemes[name], credentials[name], requirement[name], req)
+			if err != nil {
+				return nil, fmt.Errorf("failed to authenticate with %s: %w", name, err)
+			}
+			secrets = append(secrets, s)
+		}
+		return secrets, nil
+	}
+	return nil, nil
+}
+
+// satisfies reports whether a credential holds what a scheme needs
+func satisfies(scheme domain.SecurityScheme, c Credential) bool {
+	switch strings.ToLower(scheme.Type) {
+	case "apikey":
+		return c.APIKey != "" && scheme.Name != "" && (scheme.In == "header" || scheme.In == "query" || scheme.In == "cookie")
+	case "http":
+		switch strings.ToLower(scheme.Scheme) {
+		case "basic":
+			return c.Username != ""
+		case "bearer":
+			return c.Token != ""
+		}
+	case "oauth2":
+		grant, _ := oauthFlow(scheme, c)
+		return c.Token != "" || grant != ""
+	case "openidconnect":
+		return c.Token != ""
+	}
+	return false
+}
+
+// oauthFlow picks the OAuth2 flow to fetch a token with: the password flow
+// when a username is given, otherwise client credentials. It returns an
+// empty grant when neither is possible.
+func oauthFlow(scheme domain.SecurityScheme, c Credential) (string, *domain.OAuthFlow) {
+	if scheme.Flows == nil {
+		return "", nil
+	}
+	switch {
+	case scheme.Flows.Password != nil && scheme.Flows.Password.TokenURL != "" && c.Username != "":
+		return "password", scheme.Flows.Password
+	case scheme.Flows.ClientCredentials != nil && scheme.Flows.ClientCredentials.TokenURL != "" && c.ClientID != "":
+		return "client_credentials", scheme.Flows.ClientCredentials
+	}
+	return "", nil
+}
+
+// applyScheme places a scheme's credential on the request
+func (e *Executor) applyScheme(ctx context.Context, spec *domain.APISpec, scheme domain.SecurityScheme, c Credential, scopes []string, req *http.Request) (secret, error) {
+	switch strings.ToLower(scheme.Type) {
+	case "apikey":
+		switch scheme.In {
+		case "header":
+			req.Header.Set(scheme.Name, c.APIKey)
+		case "query":
+			pair := url.QueryEscape(scheme.Name) + "=" + url.QueryEscape(c.APIKey)
+			if req.URL.RawQuery != "" {
+				pair = req.URL.RawQuery + "&" + pair
+			}
+			req.URL.RawQuery = pair
+		case "cookie":
+			// Written by hand: http.Cookie would quote a value with a space or
+			// comma, and the quotes would reach the API as part of the key
+			if !cookieSafe(c.APIKey) {
+				return secret{}, fmt.Errorf("API key for cookie %s contains characters a cookie cannot carry", scheme.Name)
+			}
+			pair := scheme.Name + "=" + c.APIKey
+			if existing := req.Header.Get("Cookie"); existing != "" {
+				pair = existing + "; " + pair
+			}
+			req.Header.Set("Cookie", pair)
+		}
+		return secret{in: scheme.In, name: scheme.Name, value: c.APIKey}, nil
+	case "http":
+		if strings.EqualFold(scheme.Scheme, "basic") {
+			req.SetBasicAuth(c.Username, c.Password)
+			return secret{in: "header", name: "Authorization", value: c.Password}, nil
+		}
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+		return secret{in: "header", name: "Authorization", value: c.Token}, nil
+	}
+
+	// OAuth2 and OpenID Connect send a bearer access token
+	token := c.Token
+	if token == "" {
+		grant, flow := oauthFlow(scheme, c)
+		tokenURL, err := resolveURL(spec.BaseURL, flow.TokenURL)
+		if err != nil {
+			return secret{}, fmt.Errorf("invalid tokenUrl: %w", err)
+		}
+		token, err = e.tokens.token(ctx, e.client, tokenRequest{
+			url:        tokenURL,
+			grant:      grant,
+			scopes:     scopes,
+			credential: c,
+		})
+		if err != nil {
+			return secret{}, err
+		}
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return secret{in: "header", name: "Authorization", value: token}, nil
+}
+
+// cookieSafe reports whether a value can be sent in a Cookie header as is:
+// it must not end the cookie with a semicolon or hold control characters
+func cookieSafe(value string) bool {
+	for _, r := range value {
+		if r == ';' || r < ' ' || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveURL resolves a possibly relative URL against the API's base URL
+func resolveURL(base, ref string) (string, error) {
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	return baseURL.ResolveReference(refURL).String(), nil
+}
+
+// redact masks the credentials in a logged request. Authorization headers
+// keep their scheme so the log still shows how the request authenticated.
+func redact(log *logger.TestCaseLog, secrets []secret) {
+	for _, s := range secrets {
+		switch s.in {
+		case "header":
+			key := http.CanonicalHeaderKey(s.name)
+			value := redacted
+			if scheme, _, ok := strings.Cut(log.RequestHeaders[key], " "); ok && key == "Authorization" {
+				value = scheme + " " + redacted
+			}
+			log.RequestHeaders[key] = value
+		case "query":
+			pair := url.QueryEscape(s.name) + "=" + url.QueryEscape(s.value)
+			log.URL = strings.Replace(log.URL, pair, url.QueryEscape(s.name)+"="+redacted, 1)
+			log.QueryParams[s.name] = redacted
+		case "cookie":
+			log.RequestHeaders["Cookie"] = redactString(log.RequestHeaders["Cookie"], []secret{s})
+		}
+	}
+}
+
+// redactString masks every credential value in s, both as sent in a query
+// string and as is
+func redactString(s string, secrets []secret) string {
+	for _, secret := range secrets {
+		if secret.value == "" {
+			continue
+		}
+		s = strings.ReplaceAll(s, url.QueryEscape(secret.value), redacted)
+		s = strings.ReplaceAll(s, secret.value, redacted)
+	}
+	return s
+}
+
+// redactedError is an error whose message had credentials masked
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+
+func (e *redactedError) Unwrap() error { return e.err }
+
+// redactError masks credentials in an error, such as a *url.Error quoting
+// a query string with an API key. A *url.Error is rebuilt around the
+// redacted URL so callers can still inspect it.
+func redactError(err error, secrets []secret) error {
+	if urlErr, ok := err.(*url.Error); ok {
+		clean := *urlErr
+		clean.URL = redactString(urlErr.URL, secrets)
+		err = &clean
+	}
+	if msg := redactString(err.Error(), secrets); msg != err.Error() {
+		return &redactedError{msg: msg, err: err}
+	}
+	return err
+}
@@ -0,0 +1,122 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/BarneyRubble12/specdrill/internal/core/domain"
+)
+
+// encodeBody serialises a generated request body for the given media type.
+// It returns the encoded bytes and the Content-Type header to send, which
+// for multipart bodies carries the boundary. URL-encoded form fields follow
+// their documented encoding.
+func encodeBody(contentType string, body interface{}, encoding map[string]domain.Encoding) ([]byte, string, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		values := url.Values{}
+		for _, field := range formFields(body, encoding) {
+			values.Add(field.name, field.value)
+		}
+		return []byte(values.Encode()), contentType, nil
+	case mediaType == "multipart/form-data":
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		// Styles do not apply to multipart bodies, so arrays repeat the name
+		for _, field := range formFields(body, nil) {
+			if err := writer.WriteField(field.name, field.value); err != nil {
+				return nil, "", fmt.Errorf("failed to encode multipart body: %w", err)
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return nil, "", fmt.Errorf("failed to encode multipart body: %w", err)
+		}
+		return buf.Bytes(), writer.FormDataContentType(), nil
+	case strings.HasPrefix(mediaType, "text/"):
+		if s, ok := body.(string); ok {
+			return []byte(s), contentType, nil
+		}
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode request body: %w", err)
+	}
+	return data, contentType, nil
+}
+
+type formField struct {
+	name  string
+	value string
+}
+
+// formFields flattens an object body into form fields, JSON-encoding nested
+// objects. Array values repeat the name, unless the field's encoding joins
+// them into one value.
+func formFields(body interface{}, encoding map[string]domain.Encoding) []formField {
+	obj, ok := body.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var fields []formField
+	for _, name := range names {
+		values, isArray := obj[name].([]interface{})
+		if !isArray {
+			values = []interface{}{obj[name]}
+		}
+		if enc := encoding[name]; isArray && !enc.Exploded() {
+			items := make([]string, len(values))
+			for i, v := range values {
+				items[i] = formValue(v)
+			}
+			fields = append(fields, formField{name: name, value: strings.Join(items, formDelimiter(enc.SerializationStyle()))})
+			continue
+		}
+		for _, v := range values {
+			fields = append(fields, formField{name: name, value: formValue(v)})
+		}
+	}
+	return fields
+}
+
+// formDelimiter returns the separator a style joins array items with
+func formDelimiter(style string) string {
+	switch style {
+	case "spaceDelimited":
+		return " "
+	case "pipeDelimited":
+		return "|"
+	}
+	return ","
+}
+
+func formValue(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case nil:
+		return ""
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(val)
+		return string(data)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,115 @@
+package executor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BarneyRubble12/specdrill/internal/core/domain"
+	"github.com/BarneyRubble12/specdrill/internal/core/model"
+)
+
+// OperationDrift summarises the undocumented behaviour seen for one
+// operation over a run
+type OperationDrift struct {
+	Method   string
+	Path     string
+	Warnings []DriftWarning
+}
+
+// DriftWarning is a distinct drift finding and how many responses showed it
+type DriftWarning struct {
+	Message string
+	Count   int
+}
+
+// driftLog accumulates drift findings per operation
+type driftLog struct {
+	mu         sync.Mutex
+	operations map[string]map[string]int
+}
+
+func (l *driftLog) record(method, path string, drift []model.Violation) {
+	if len(drift) == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.operations == nil {
+		l.operations = make(map[string]map[string]int)
+	}
+	key := method + " " + path
+	if l.operations[key] == nil {
+		l.operations[key] = make(map[string]int)
+	}
+	for _, warning := range drift {
+		l.operations[key][warning.String()]++
+	}
+}
+
+func (l *driftLog) summary() []OperationDrift {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	summary := make([]OperationDrift, 0, len(l.operations))
+	for key, messages := range l.operations {
+		method, path, _ := strings.Cut(key, " ")
+		operation := OperationDrift{Method: method, Path: path}
+		for message, count := range messages {
+			operation.Warnings = append(operation.Warnings, DriftWarning{Message: message, Count: count})
+		}
+		sort.Slice(operation.Warnings, func(i, j int) bool {
+			return operation.Warnings[i].Message < operation.Warnings[j].Message
+		})
+		summary = append(summary, operation)
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		if summary[i].Path != summary[j].Path {
+			return summary[i].Path < summary[j].Path
+		}
+		return summary[i].Method < summary[j].Method
+	})
+	return summary
+}
+
+// DriftSummary returns the drift found so far, per operation. It is empty
+// unless the executor runs in drift mode.
+func (e *Executor) DriftSummary() []OperationDrift {
+	return e.drift.summary()
+}
+
+// hasResponse reports whether an operation documents a response key
+func hasResponse(operation *domain.Operation, status string) bool {
+	if operation == nil {
+		return false
+	}
+	_, ok := operation.Responses[status]
+	return ok
+}
+
+// inExpectedClass reports whether a status code falls in the same class,
+// e.g. 2xx, as one of the statuses a case expects
+func inExpectedClass(testCase model.TestCase, code int) bool {
+	class := byte('0' + code/100)
+	for _, expected := range testCase.ExpectedStatuses {
+		if expected != "" && expected[0] == class {
+			return true
+		}
+	}
+	return false
+}
+
+// undocumentedStatus reports a status code the operation does not document
+func undocumentedStatus(testCase model.TestCase, code int) model.Violation {
+	expected := "a documented status"
+	if len(testCase.DocumentedStatuses) > 0 {
+		expected = "one of " + strings.Join(testCase.DocumentedStatuses, ", ")
+	}
+	return model.Violation{
+		Keyword:  "status",
+		Expected: expected,
+		Actual:   fmt.Sprint(code),
+	}
+}
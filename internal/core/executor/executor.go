@@ -2,31 +2,91 @@ package executor
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/BarneyRubble12/specdrill/internal/core/domain"
+	"github.com/BarneyRubble12/specdrill/internal/core/generator"
 	"github.com/BarneyRubble12/specdrill/internal/core/logger"
+	"github.com/BarneyRubble12/specdrill/internal/core/model"
+	"github.com/BarneyRubble12/specdrill/internal/core/validator"
 )
 
-// Executor handles the execution of API tests
+// Options controls how test cases are executed and judged
+type Options struct {
+	// Drift reports undocumented status codes, undeclared properties and
+	// enum values outside the declared set as warnings instead of failures
+	Drift bool
+	// Timeout limits how long a single request may take; zero means no limit
+	Timeout time.Duration
+	// Workers is how many cases RunSuite sends at once; values below 1
+	// mean one at a time
+	Workers int
+	// MaxPerHost caps the requests in flight to any one host; zero means no
+	// cap
+	MaxPerHost int
+	// RateLimit caps requests per second across the run; zero means no
+	// limit
+	RateLimit float64
+	// OperationRateLimit caps requests per second to each operation; zero
+	// means no limit
+	OperationRateLimit float64
+	// RetryBudget is how long a case may wait in total on the Retry-After
+	// of 429 and 503 responses, retrying after each; zero disables retries
+	RetryBudget time.Duration
+	// Retry retries connection errors and transient statuses
+	Retry RetryPolicy
+	// Credentials holds the secrets for each security scheme by name, from
+	// which the operation's security requirements are satisfied
+	Credentials map[string]Credential
+}
+
+// Executor handles the execution of API tests. It is safe for concurrent
+// use.
 type Executor struct {
-	client *http.Client
+	client    *http.Client
+	generator *generator.Generator
+	validator *validator.Validator
+	options   Options
+	drift     driftLog
+	hosts     hostLimiter
+	limiter   *rateLimiter
+	tokens    tokenCache
 }
 
 // NewExecutor creates a new Executor instance
-func NewExecutor() *Executor {
+func NewExecutor(generator *generator.Generator, validator *validator.Validator, options Options) *Executor {
 	return &Executor{
-		client: &http.Client{},
+		client:    &http.Client{Timeout: options.Timeout},
+		generator: generator,
+		validator: validator,
+		options:   options,
+		hosts:     hostLimiter{limit: options.MaxPerHost},
+		limiter:   newRateLimiter(options.RateLimit, options.OperationRateLimit),
 	}
 }
 
 // ExecuteTest runs a test case against the API
-func (e *Executor) ExecuteTest(spec *domain.APISpec, path string, method string) (*TestResult, error) {
+func (e *Executor) ExecuteTest(ctx context.Context, spec *domain.APISpec, path string, method string) (*model.TestResult, error) {
+	// Build the test case for the operation, including parameters and any
+	// request body
+	testCase := e.generator.CreateTestCase(method, path, spec.Paths[path])
+	return e.ExecuteCase(ctx, spec, testCase)
+}
+
+// ExecuteCase sends a prepared test case to the API and judges the response.
+// The request is abandoned when ctx is done. If no response is received the
+// error is returned along with a result recording the attempts made.
+func (e *Executor) ExecuteCase(ctx context.Context, spec *domain.APISpec, testCase model.TestCase) (*model.TestResult, error) {
+	// Imported cases may leave out what the spec documents
+	testCase = e.generator.CompleteTestCase(spec.Paths[testCase.Path], testCase)
+	method, path := testCase.Method, testCase.Path
+
 	// Construct the full URL
 	baseURL := spec.BaseURL
 	if !strings.HasSuffix(baseURL, "/") {
@@ -37,7 +97,7 @@ func (e *Executor) ExecuteTest(spec *domain.APISpec, path string, method string)
 	}
 
 	// Replace path parameters with actual values
-	path = replacePathParams(path)
+	path, pathParams := expandPath(path, testCase.Params)
 
 	fullURL := baseURL + path
 
@@ -45,18 +105,25 @@ func (e *Executor) ExecuteTest(spec *domain.APISpec, path string, method string)
 	var req *http.Request
 	var err error
 
-	// Analyze the spec to determine if a request body is required
-	// For simplicity, we'll assume POST/PUT/PATCH methods require a body
+	// Only send a body when the operation declares one
 	var body []byte
-	if method == "POST" || method == "PUT" || method == "PATCH" {
-		// Generate a simple JSON body for demonstration
-		body = []byte(`{"name": "test"}`)
+	var contentType string
+	operation := spec.Paths[testCase.Path].Operation(method)
+	if testCase.ContentType != "" {
+		var encoding map[string]domain.Encoding
+		if operation != nil && operation.RequestBody != nil {
+			encoding = operation.RequestBody.Content[testCase.ContentType].Encoding
+		}
+		body, contentType, err = encodeBody(testCase.ContentType, testCase.RequestBody, encoding)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if body != nil {
-		req, err = http.NewRequest(method, fullURL, bytes.NewBuffer(body))
+		req, err = http.NewRequestWithContext(ctx, method, fullURL, bytes.NewReader(body))
 	} else {
-		req, err = http.NewRequest(method, fullURL, nil)
+		req, err = http.NewRequestWithContext(ctx, method, fullURL, nil)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -64,44 +131,79 @@ func (e *Executor) ExecuteTest(spec *domain.APISpec, path string, method string)
 
 	// Add headers
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Content-Type", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	applyParams(req, testCase.Params)
+
+	// Add the credentials the operation's security requires
+	secrets, err := e.authorize(ctx, spec, operation, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to authorize request: %w", err)
+	}
 
 	// Create test case log
 	testLog := logger.TestCaseLog{
-		Name:           fmt.Sprintf("%s %s", method, path),
+		Name:           testCase.Name,
 		Endpoint:       fullURL,
+		URL:            req.URL.String(),
 		Method:         method,
-		PathParams:     extractPathParams(path),
+		PathParams:     pathParams,
 		QueryParams:    extractQueryParams(req.URL),
 		RequestHeaders: extractHeaders(req.Header),
 		RequestBody:    string(body),
 	}
+	redact(&testLog, secrets)
 
 	// Execute the request
-	resp, err := e.client.Do(req)
-	if err != nil {
-		testLog.Error = err.Error()
-		logger.LogTestCase(testLog)
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+	start := time.Now()
+	resp, respBody, attempts, err := e.send(req, body, method+" "+testCase.Path)
+	// Errors quote the URL, which may carry an API key. Earlier attempts may
+	// have failed even when a retry succeeded.
+	for i := range attempts {
+		attempts[i].Error = redactString(attempts[i].Error, secrets)
 	}
-	defer resp.Body.Close()
-
-	// Read the response body
-	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
+		err = redactError(err, secrets)
 		testLog.Error = err.Error()
 		logger.LogTestCase(testLog)
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		err = fmt.Errorf("failed to execute request: %w", err)
+		return &model.TestResult{
+			TestCase: testCase,
+			URL:      fullURL,
+			Error:    err,
+			Duration: time.Since(start).Milliseconds(),
+			Attempts: attempts,
+			Request:  testLog,
+		}, err
 	}
 
 	// Create the test result
-	result := &TestResult{
+	result := &model.TestResult{
+		TestCase:   testCase,
 		URL:        fullURL,
-		Method:     method,
 		StatusCode: resp.StatusCode,
 		Headers:    resp.Header,
 		Body:       string(respBody),
+		Duration:   time.Since(start).Milliseconds(),
+		Attempts:   attempts,
+	}
+	result.MatchedStatus, result.Passed = testCase.MatchStatus(resp.StatusCode)
+
+	// Check the headers and body against the documented response
+	switch {
+	case e.options.Drift && !hasResponse(operation, result.MatchedStatus):
+		// An undocumented status is drift; the case still passes when it is
+		// of the kind expected, e.g. a 201 where only 200 is documented
+		result.Drift = []model.Violation{undocumentedStatus(testCase, resp.StatusCode)}
+		result.Passed = result.Passed || inExpectedClass(testCase, resp.StatusCode)
+	case e.options.Drift:
+		result.Violations, result.Drift = e.validator.CheckDrift(spec, operation, method, resp.StatusCode, result.MatchedStatus, resp.Header, respBody)
+	case result.MatchedStatus != "":
+		result.Violations = e.validator.ValidateResponse(spec, operation, method, resp.StatusCode, result.MatchedStatus, resp.Header, respBody)
 	}
+	result.Passed = result.Passed && len(result.Violations) == 0
+	e.drift.record(method, testCase.Path, result.Drift)
 
 	// Check if the response is valid JSON
 	var jsonBody interface{}
@@ -115,25 +217,11 @@ func (e *Executor) ExecuteTest(spec *domain.APISpec, path string, method string)
 	testLog.ResponseStatus = resp.StatusCode
 	testLog.ResponseBody = string(respBody)
 	logger.LogTestCase(testLog)
+	result.Request = testLog
 
 	return result, nil
 }
 
-// replacePathParams replaces path parameters with actual values
-func replacePathParams(path string) string {
-	// For demonstration, replace {id} with a sample value
-	return strings.Replace(path, "{id}", "1", -1)
-}
-
-// extractPathParams extracts path parameters from the path
-func extractPathParams(path string) map[string]string {
-	params := make(map[string]string)
-	if strings.Contains(path, "{id}") {
-		params["id"] = "1"
-	}
-	return params
-}
-
 // extractQueryParams extracts query parameters from the URL
 func extractQueryParams(u *url.URL) map[string]string {
 	params := make(map[string]string)
@@ -154,13 +242,3 @@ func extractHeaders(h http.Header) map[string]string {
 	}
 	return headers
 }
-
-// TestResult represents the result of a test execution
-type TestResult struct {
-	URL         string
-	Method      string
-	StatusCode  int
-	Headers     http.Header
-	Body        string
-	IsValidJSON bool
-}
@@ -1,21 +1,32 @@
 package executor
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/BarneyRubble12/specdrill/internal/core/domain"
+	"github.com/BarneyRubble12/specdrill/internal/core/generator"
+	"github.com/BarneyRubble12/specdrill/internal/core/model"
+	"github.com/BarneyRubble12/specdrill/internal/core/parser"
+	"github.com/BarneyRubble12/specdrill/internal/core/validator"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestExecuteTest(t *testing.T) {
 	// Create a test server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Check request headers
+		// Check request headers; no body is sent, so no Content-Type either
 		assert.Equal(t, "application/json", r.Header.Get("Accept"))
-		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Empty(t, r.Header.Get("Content-Type"))
 
 		// Return a test response
 		response := map[string]interface{}{
@@ -37,14 +48,14 @@ func TestExecuteTest(t *testing.T) {
 		method   string
 		wantErr  bool
 		errMsg   string
-		validate func(*testing.T, *TestResult)
+		validate func(*testing.T, *model.TestResult)
 	}{
 		{
 			name:    "Successful GET request",
 			path:    "/test",
 			method:  "GET",
 			wantErr: false,
-			validate: func(t *testing.T, result *TestResult) {
+			validate: func(t *testing.T, result *model.TestResult) {
 				assert.Equal(t, http.StatusOK, result.StatusCode)
 				assert.True(t, result.IsValidJSON)
 				assert.Contains(t, result.Body, "test response")
@@ -55,7 +66,7 @@ func TestExecuteTest(t *testing.T) {
 			path:    "/test",
 			method:  "GET",
 			wantErr: false,
-			validate: func(t *testing.T, result *TestResult) {
+			validate: func(t *testing.T, result *model.TestResult) {
 				assert.Equal(t, http.StatusOK, result.StatusCode)
 				assert.True(t, result.IsValidJSON)
 			},
@@ -65,7 +76,7 @@ func TestExecuteTest(t *testing.T) {
 			path:    "test",
 			method:  "GET",
 			wantErr: false,
-			validate: func(t *testing.T, result *TestResult) {
+			validate: func(t *testing.T, result *model.TestResult) {
 				assert.Equal(t, http.StatusOK, result.StatusCode)
 				assert.True(t, result.IsValidJSON)
 			},
@@ -74,8 +85,8 @@ func TestExecuteTest(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			executor := NewExecutor()
-			result, err := executor.ExecuteTest(spec, tt.path, tt.method)
+			executor := NewExecutor(generator.NewGenerator(generator.Options{}), validator.NewValidator(), Options{})
+			result, err := executor.ExecuteTest(context.Background(), spec, tt.path, tt.method)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -87,7 +98,7 @@ func TestExecuteTest(t *testing.T) {
 
 			assert.NoError(t, err)
 			assert.NotNil(t, result)
-			assert.Equal(t, tt.method, result.Method)
+			assert.Equal(t, tt.method, result.TestCase.Method)
 			assert.Equal(t, "application/json", result.Headers.Get("Content-Type"))
 
 			if tt.validate != nil {
@@ -96,3 +107,1240 @@ func TestExecuteTest(t *testing.T) {
 		})
 	}
 }
+
+func TestExecuteTestRequestBody(t *testing.T) {
+	type received struct {
+		contentType string
+		body        string
+	}
+	requests := make(map[string]received)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests[r.URL.Path] = received{contentType: r.Header.Get("Content-Type"), body: string(body)}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	minLength := 3
+	spec := &domain.APISpec{
+		BaseURL: server.URL,
+		Paths: map[string]domain.PathItem{
+			"/pets": {
+				Post: &domain.Operation{
+					RequestBody: &domain.RequestBody{
+						Content: map[string]domain.MediaType{
+							"application/json": {
+								Schema: domain.Schema{
+									Type:     "object",
+									Required: []string{"name"},
+									Properties: map[string]domain.Schema{
+										"name": {Type: "string", MinLength: &minLength, Pattern: "^[a-z]+$"},
+										"kind": {Type: "string", Enum: []interface{}{"cat", "dog"}},
+										"id":   {Type: "integer", ReadOnly: true},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"/forms": {
+				Put: &domain.Operation{
+					RequestBody: &domain.RequestBody{
+						Content: map[string]domain.MediaType{
+							"application/x-www-form-urlencoded": {
+								Example: map[string]interface{}{"name": "Rex", "tags": []interface{}{"a", "b"}},
+							},
+						},
+					},
+				},
+			},
+			"/ping": {
+				Post: &domain.Operation{},
+			},
+		},
+	}
+
+	executor := NewExecutor(generator.NewGenerator(generator.Options{}), validator.NewValidator(), Options{})
+	for _, tc := range []struct{ path, method string }{
+		{"/pets", "POST"},
+		{"/forms", "PUT"},
+		{"/ping", "POST"},
+	} {
+		result, err := executor.ExecuteTest(context.Background(), spec, tc.path, tc.method)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, result.StatusCode)
+		assert.True(t, result.Passed)
+	}
+
+	assert.Equal(t, "application/json", requests["/pets"].contentType)
+	assert.JSONEq(t, `{"name": "aaa", "kind": "cat"}`, requests["/pets"].body)
+
+	assert.Equal(t, "application/x-www-form-urlencoded", requests["/forms"].contentType)
+	assert.Equal(t, "name=Rex&tags=a&tags=b", requests["/forms"].body)
+
+	// Operations without a declared request body get no body
+	assert.Empty(t, requests["/ping"].contentType)
+	assert.Empty(t, requests["/ping"].body)
+}
+
+func TestExecuteTestPathParams(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	spec := &domain.APISpec{
+		BaseURL: server.URL,
+		Paths: map[string]domain.PathItem{
+			"/pets/{petId}": {
+				Parameters: []domain.Parameter{
+					{Name: "petId", In: "path", Required: true, Schema: domain.Schema{Type: "integer", Format: "int64"}},
+				},
+				Get: &domain.Operation{},
+				Delete: &domain.Operation{
+					Parameters: []domain.Parameter{
+						{Name: "petId", In: "path", Required: true, Schema: domain.Schema{Type: "string", Format: "uuid"}},
+					},
+				},
+			},
+			"/owners/{name}/tags/{tags}": {
+				Get: &domain.Operation{
+					Parameters: []domain.Parameter{
+						{Name: "name", In: "path", Required: true, Example: "Ann Lee/Jr"},
+						{Name: "tags", In: "path", Required: true, Schema: domain.Schema{Type: "array", Items: &domain.Schema{Enum: []interface{}{"a", "b"}}}},
+					},
+				},
+			},
+			"/undeclared/{id}": {
+				Get: &domain.Operation{},
+			},
+		},
+	}
+
+	executor := NewExecutor(generator.NewGenerator(generator.Options{}), validator.NewValidator(), Options{})
+	for _, tc := range []struct{ path, method string }{
+		{"/pets/{petId}", "GET"},
+		{"/pets/{petId}", "DELETE"},
+		{"/owners/{name}/tags/{tags}", "GET"},
+		{"/undeclared/{id}", "GET"},
+	} {
+		_, err := executor.ExecuteTest(context.Background(), spec, tc.path, tc.method)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{
+		"/pets/1",
+		"/pets/123e4567-e89b-12d3-a456-426614174000",
+		"/owners/Ann%20Lee%2FJr/tags/a",
+		"/undeclared/1",
+	}, paths)
+}
+
+func TestApplyParams(t *testing.T) {
+	colors := []interface{}{"blue", "black"}
+	point := map[string]interface{}{"x": float64(1), "y": "a b"}
+
+	tests := []struct {
+		name   string
+		params []model.Param
+		query  string
+		header http.Header
+	}{
+		{
+			name:   "Form exploded array",
+			params: []model.Param{{Name: "color", In: "query", Style: "form", Explode: true, Value: colors}},
+			query:  "color=blue&color=black",
+		},
+		{
+			name:   "Form array",
+			params: []model.Param{{Name: "color", In: "query", Style: "form", Value: colors}},
+			query:  "color=blue,black",
+		},
+		{
+			name:   "Form exploded object",
+			params: []model.Param{{Name: "point", In: "query", Style: "form", Explode: true, Value: point}},
+			query:  "x=1&y=a+b",
+		},
+		{
+			name:   "Form object",
+			params: []model.Param{{Name: "point", In: "query", Style: "form", Value: point}},
+			query:  "point=x,1,y,a+b",
+		},
+		{
+			name:   "Space delimited array",
+			params: []model.Param{{Name: "color", In: "query", Style: "spaceDelimited", Value: colors}},
+			query:  "color=blue%20black",
+		},
+		{
+			name:   "Pipe delimited array",
+			params: []model.Param{{Name: "color", In: "query", Style: "pipeDelimited", Value: colors}},
+			query:  "color=blue|black",
+		},
+		{
+			name:   "Deep object",
+			params: []model.Param{{Name: "point", In: "query", Style: "deepObject", Explode: true, Value: point}},
+			query:  "point%5Bx%5D=1&point%5By%5D=a+b",
+		},
+		{
+			name: "Headers and cookies",
+			params: []model.Param{
+				{Name: "X-Colors", In: "header", Style: "simple", Value: colors},
+				{Name: "X-Point", In: "header", Style: "simple", Explode: true, Value: point},
+				{Name: "Accept", In: "header", Style: "simple", Value: "text/plain"},
+				{Name: "session", In: "cookie", Style: "form", Explode: true, Value: "a;b"},
+				{Name: "color", In: "cookie", Style: "form", Value: colors},
+			},
+			header: http.Header{
+				"X-Colors": {"blue,black"},
+				"X-Point":  {"x=1,y=a b"},
+				"Cookie":   {"session=a%3Bb; color=blue,black"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+			applyParams(req, tt.params)
+			assert.Equal(t, tt.query, req.URL.RawQuery)
+			if tt.header == nil {
+				tt.header = http.Header{}
+			}
+			assert.Equal(t, tt.header, req.Header)
+		})
+	}
+}
+
+func TestExecuteTestExpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	spec := &domain.APISpec{
+		BaseURL: server.URL,
+		Paths: map[string]domain.PathItem{
+			"/pets": {
+				Get:  &domain.Operation{Responses: map[string]domain.Response{"200": {}, "default": {}}},
+				Post: &domain.Operation{Responses: map[string]domain.Response{"201": {}, "400": {}}},
+			},
+		},
+	}
+
+	executor := NewExecutor(generator.NewGenerator(generator.Options{}), validator.NewValidator(), Options{})
+
+	result, err := executor.ExecuteTest(context.Background(), spec, "/pets", "GET")
+	assert.NoError(t, err)
+	assert.True(t, result.Passed)
+	assert.Equal(t, "200", result.MatchedStatus)
+
+	// A 200 where only 201 is documented fails
+	result, err = executor.ExecuteTest(context.Background(), spec, "/pets", "POST")
+	assert.NoError(t, err)
+	assert.False(t, result.Passed)
+	assert.Equal(t, []string{"201"}, result.TestCase.ExpectedStatuses)
+	assert.Empty(t, result.MatchedStatus)
+}
+
+func TestExecuteTestResponseSchema(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "seven"}`))
+	}))
+	defer server.Close()
+
+	spec := &domain.APISpec{
+		BaseURL: server.URL,
+		Paths: map[string]domain.PathItem{
+			"/pets": {
+				Get: &domain.Operation{Responses: map[string]domain.Response{
+					"200": {Content: map[string]domain.MediaType{
+						"application/json": {Schema: domain.Schema{
+							Type:       "object",
+							Properties: map[string]domain.Schema{"id": {Type: "integer"}},
+						}},
+					}},
+				}},
+			},
+		},
+	}
+
+	executor := NewExecutor(generator.NewGenerator(generator.Options{}), validator.NewValidator(), Options{})
+	result, err := executor.ExecuteTest(context.Background(), spec, "/pets", "GET")
+	assert.NoError(t, err)
+	assert.Equal(t, "200", result.MatchedStatus)
+	assert.False(t, result.Passed)
+	assert.Equal(t, []model.Violation{{Pointer: "/id", Keyword: "type", Expected: "integer", Actual: "string"}}, result.Violations)
+}
+
+func TestExecuteTestDrift(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusTeapot)
+			return
+		case http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "colour": "brown"}`))
+	}))
+	defer server.Close()
+
+	spec := &domain.APISpec{
+		BaseURL: server.URL,
+		Paths: map[string]domain.PathItem{
+			"/pets": {
+				Get: &domain.Operation{Responses: map[string]domain.Response{
+					"200": {Content: map[string]domain.MediaType{
+						"application/json": {Schema: domain.Schema{
+							Type:       "object",
+							Properties: map[string]domain.Schema{"id": {Type: "integer"}},
+						}},
+					}},
+				}},
+				Post:   &domain.Operation{Responses: map[string]domain.Response{"200": {}}},
+				Delete: &domain.Operation{Responses: map[string]domain.Response{"204": {}}},
+			},
+		},
+	}
+
+	executor := NewExecutor(generator.NewGenerator(generator.Options{}), validator.NewValidator(), Options{Drift: true})
+	for i := 0; i < 2; i++ {
+		result, err := executor.ExecuteTest(context.Background(), spec, "/pets", "GET")
+		assert.NoError(t, err)
+		assert.True(t, result.Passed)
+		assert.Len(t, result.Drift, 1)
+	}
+
+	result, err := executor.ExecuteTest(context.Background(), spec, "/pets", "DELETE")
+	assert.NoError(t, err)
+	assert.False(t, result.Passed)
+	assert.Equal(t, []model.Violation{{Keyword: "status", Expected: "one of 204", Actual: "418"}}, result.Drift)
+
+	// An undocumented success is drift, not a failure
+	result, err = executor.ExecuteTest(context.Background(), spec, "/pets", "POST")
+	assert.NoError(t, err)
+	assert.True(t, result.Passed)
+	assert.Empty(t, result.Violations)
+	assert.Equal(t, []model.Violation{{Keyword: "status", Expected: "one of 200", Actual: "201"}}, result.Drift)
+
+	assert.Equal(t, []OperationDrift{
+		{Method: "DELETE", Path: "/pets", Warnings: []DriftWarning{{Message: "status: expected one of 204, got 418", Count: 1}}},
+		{Method: "GET", Path: "/pets", Warnings: []DriftWarning{{Message: "/colour: additionalProperties: expected declared properties only, got property colour", Count: 2}}},
+		{Method: "POST", Path: "/pets", Warnings: []DriftWarning{{Message: "status: expected one of 200, got 201", Count: 1}}},
+	}, executor.DriftSummary())
+}
+
+func TestExecuteCaseNegative(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Rejects a missing limit but accepts any value for it
+		if r.URL.Query().Get("limit") == "" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	pathItem := domain.PathItem{
+		Get: &domain.Operation{
+			Parameters: []domain.Parameter{
+				{Name: "limit", In: "query", Required: true, Schema: domain.Schema{Type: "integer", Maximum: floatPtr(10)}},
+			},
+			Responses: map[string]domain.Response{"200": {}, "400": {}},
+		},
+	}
+	spec := &domain.APISpec{BaseURL: server.URL, Paths: map[string]domain.PathItem{"/pets": pathItem}}
+
+	gen := generator.NewGenerator(generator.Options{})
+	executor := NewExecutor(gen, validator.NewValidator(), Options{})
+
+	passed := make(map[string]bool)
+	for _, testCase := range gen.NegativeCases("GET", "/pets", pathItem) {
+		result, err := executor.ExecuteCase(context.Background(), spec, testCase)
+		assert.NoError(t, err)
+		assert.True(t, result.TestCase.Negative)
+		passed[testCase.Description] = result.Passed
+	}
+	assert.Equal(t, map[string]bool{
+		"missing required query parameter limit":                 true,
+		"query parameter limit of the wrong type (want integer)": false,
+		"query parameter limit not an integer":                   false,
+		"query parameter limit above maximum 10":                 false,
+	}, passed)
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestRunSuite(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/boom" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// The suite's base URL takes precedence over the spec's
+	spec := &domain.APISpec{BaseURL: "http://127.0.0.1:1"}
+	suite := model.TestSuite{
+		Name:    "Pets",
+		BaseURL: server.URL,
+		TestCases: []model.TestCase{
+			{Name: "GET /pets", Method: "GET", Path: "/pets", ExpectedStatuses: []string{"200"}},
+			{Name: "GET /boom", Method: "GET", Path: "/boom", ExpectedStatuses: []string{"200"}},
+			{Name: "POST /pets", Method: "POST", Path: "/pets", ContentType: "application/json", RequestBody: make(chan int), ExpectedStatuses: []string{"200"}},
+		},
+	}
+
+	executor := NewExecutor(generator.NewGenerator(generator.Options{}), validator.NewValidator(), Options{})
+	summary := executor.RunSuite(context.Background(), spec, suite)
+
+	assert.Equal(t, 3, summary.TotalTests)
+	assert.Equal(t, 1, summary.PassedTests)
+	assert.Equal(t, 2, summary.FailedTests)
+	assert.GreaterOrEqual(t, summary.Duration, int64(0))
+	assert.Len(t, summary.Results, 3)
+
+	assert.True(t, summary.Results[0].Passed)
+	assert.Equal(t, "GET /pets", summary.Results[0].TestCase.Name)
+	assert.Equal(t, server.URL+"/pets", summary.Results[0].URL)
+	assert.Equal(t, http.StatusInternalServerError, summary.Results[1].StatusCode)
+	assert.False(t, summary.Results[1].Passed)
+	assert.Error(t, summary.Results[2].Error)
+	assert.False(t, summary.Results[2].Passed)
+}
+
+func TestRunSuiteConcurrent(t *testing.T) {
+	var inFlight, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var cases []model.TestCase
+	for i := 0; i < 24; i++ {
+		testCase := model.TestCase{Name: fmt.Sprintf("case %d", i), Method: "GET", Path: "/pets", ExpectedStatuses: []string{"200"}}
+		if i%5 == 0 {
+			testCase.Params = []model.Param{{Name: "fail", In: "query", Value: "yes"}}
+		}
+		cases = append(cases, testCase)
+	}
+	spec := &domain.APISpec{BaseURL: server.URL}
+
+	tests := []struct {
+		name     string
+		options  Options
+		wantPeak int32
+	}{
+		{name: "Serial", options: Options{}, wantPeak: 1},
+		{name: "Worker pool", options: Options{Workers: 8}, wantPeak: 8},
+		{name: "Per-host cap", options: Options{Workers: 8, MaxPerHost: 3}, wantPeak: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&peak, 0)
+			executor := NewExecutor(generator.NewGenerator(generator.Options{}), validator.NewValidator(), tt.options)
+			summary := executor.RunSuite(context.Background(), spec, model.TestSuite{TestCases: cases})
+
+			assert.LessOrEqual(t, atomic.LoadInt32(&peak), tt.wantPeak)
+			if tt.options.Workers > 1 {
+				assert.Greater(t, atomic.LoadInt32(&peak), int32(1))
+			}
+			assert.Equal(t, 24, summary.TotalTests)
+			assert.Equal(t, 19, summary.PassedTests)
+			assert.Equal(t, 5, summary.FailedTests)
+			// Results keep the order of the cases whatever the scheduling
+			for i, result := range summary.Results {
+				assert.Equal(t, fmt.Sprintf("case %d", i), result.TestCase.Name)
+				assert.Equal(t, i%5 != 0, result.Passed)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		status   int
+		header   string
+		wantWait time.Duration
+		wantOK   bool
+	}{
+		{name: "Seconds", status: http.StatusTooManyRequests, header: "3", wantWait: 3 * time.Second, wantOK: true},
+		{name: "HTTP date", status: http.StatusServiceUnavailable, header: "Mon, 01 Jan 2024 12:00:05 GMT", wantWait: 5 * time.Second, wantOK: true},
+		{name: "Date in the past", status: http.StatusTooManyRequests, header: "Mon, 01 Jan 2024 11:00:00 GMT", wantOK: true},
+		{name: "No header", status: http.StatusTooManyRequests},
+		{name: "Unparsable", status: http.StatusTooManyRequests, header: "soon"},
+		{name: "Other status", status: http.StatusInternalServerError, header: "3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.header != "" {
+				resp.Header.Set("Retry-After", tt.header)
+			}
+			wait, ok := retryAfter(resp, now)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantWait, wait)
+		})
+	}
+}
+
+func TestExecuteCaseThrottled(t *testing.T) {
+	tests := []struct {
+		name         string
+		retryAfter   string
+		budget       time.Duration
+		wantAttempts []int
+		wantPassed   bool
+	}{
+		{
+			name:         "Retried until accepted",
+			retryAfter:   "0",
+			budget:       time.Second,
+			wantAttempts: []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusOK},
+			wantPassed:   true,
+		},
+		{
+			name:         "Wait beyond the budget",
+			retryAfter:   "5",
+			budget:       time.Second,
+			wantAttempts: []int{http.StatusTooManyRequests},
+		},
+		{
+			name:         "Retries disabled",
+			retryAfter:   "0",
+			wantAttempts: []int{http.StatusTooManyRequests},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				assert.Equal(t, `{"name":"Rex"}`, string(body))
+				if atomic.AddInt32(&requests, 1) <= 2 {
+					w.Header().Set("Retry-After", tt.retryAfter)
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			executor := NewExecutor(generator.NewGenerator(generator.Options{}), validator.NewValidator(), Options{RetryBudget: tt.budget})
+			result, err := executor.ExecuteCase(context.Background(), &domain.APISpec{BaseURL: server.URL}, model.TestCase{
+				Name:             "POST /pets",
+				Method:           "POST",
+				Path:             "/pets",
+				ContentType:      "application/json",
+				RequestBody:      map[string]interface{}{"name": "Rex"},
+				ExpectedStatuses: []string{"200"},
+			})
+			assert.NoError(t, err)
+
+			var statuses []int
+			for _, attempt := range result.Attempts {
+				statuses = append(statuses, attempt.StatusCode)
+			}
+			assert.Equal(t, tt.wantAttempts, statuses)
+			assert.Equal(t, tt.wantAttempts[len(tt.wantAttempts)-1], result.StatusCode)
+			assert.Equal(t, tt.wantPassed, result.Passed)
+		})
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	bucket := newTokenBucket(2)
+	// A second's worth of requests may go at once, then they are paced
+	assert.Zero(t, bucket.reserve())
+	assert.Zero(t, bucket.reserve())
+	assert.InDelta(t, 500*time.Millisecond, bucket.reserve(), float64(50*time.Millisecond))
+	assert.InDelta(t, time.Second, bucket.reserve(), float64(50*time.Millisecond))
+
+	// Per-operation limits are independent of each other
+	limiter := newRateLimiter(0, 1)
+	start := time.Now()
+	limiter.wait(context.Background(), "GET /pets")
+	limiter.wait(context.Background(), "GET /owners")
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestRunSuiteInterrupted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		case "/throttled":
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "Hung request", path: "/slow"},
+		{name: "Waiting on Retry-After", path: "/throttled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suite := model.TestSuite{TestCases: []model.TestCase{
+				{Name: "fast", Method: "GET", Path: "/fast", ExpectedStatuses: []string{"200"}},
+				{Name: "stuck", Method: "GET", Path: tt.path, ExpectedStatuses: []string{"200"}},
+				{Name: "never started", Method: "GET", Path: "/fast", ExpectedStatuses: []string{"200"}},
+			}}
+			executor := NewExecutor(generator.NewGenerator(generator.Options{}), validator.NewValidator(), Options{RetryBudget: time.Minute})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			summary := executor.RunSuite(ctx, &domain.APISpec{BaseURL: server.URL}, suite)
+
+			assert.Less(t, time.Since(start), 2*time.Second)
+			assert.True(t, summary.Interrupted)
+			assert.Equal(t, 1, summary.TotalTests)
+			assert.Equal(t, 1, summary.PassedTests)
+			assert.Equal(t, 2, summary.Skipped)
+			assert.Equal(t, "fast", summary.Results[0].TestCase.Name)
+		})
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, Statuses: []int{502}, BaseDelay: time.Millisecond}
+
+	tests := []struct {
+		name         string
+		method       string
+		policy       RetryPolicy
+		closed       bool
+		wantAttempts []int
+		wantErr      bool
+		wantPassed   bool
+	}{
+		{
+			name:         "Transient status retried",
+			method:       "GET",
+			policy:       policy,
+			wantAttempts: []int{502, 502, 200},
+			wantPassed:   true,
+		},
+		{
+			name:         "Attempts exhausted",
+			method:       "GET",
+			policy:       RetryPolicy{MaxAttempts: 2, Statuses: []int{502}},
+			wantAttempts: []int{502, 502},
+		},
+		{
+			name:         "Non-idempotent method not retried",
+			method:       "POST",
+			policy:       policy,
+			wantAttempts: []int{502},
+		},
+		{
+			name:         "Non-idempotent method retried when allowed",
+			method:       "POST",
+			policy:       RetryPolicy{MaxAttempts: 3, Statuses: []int{502}, AllMethods: true},
+			wantAttempts: []int{502, 502, 200},
+			wantPassed:   true,
+		},
+		{
+			name:         "Status not in the policy",
+			method:       "GET",
+			policy:       RetryPolicy{MaxAttempts: 3, Statuses: []int{504}},
+			wantAttempts: []int{502},
+		},
+		{
+			name:         "Connection errors retried",
+			method:       "GET",
+			policy:       policy,
+			closed:       true,
+			wantAttempts: []int{0, 0, 0},
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&requests, 1) <= 2 {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			if tt.closed {
+				server.Close()
+			}
+			defer server.Close()
+
+			executor := NewExecutor(generator.NewGenerator(generator.Options{}), validator.NewValidator(), Options{Retry: tt.policy})
+			result, err := executor.ExecuteCase(context.Background(), &domain.APISpec{BaseURL: server.URL}, model.TestCase{
+				Name:             tt.method + " /pets",
+				Method:           tt.method,
+				Path:             "/pets",
+				ExpectedStatuses: []string{"200"},
+			})
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, err, result.Error)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			var statuses []int
+			for _, attempt := range result.Attempts {
+				statuses = append(statuses, attempt.StatusCode)
+				assert.Equal(t, attempt.StatusCode == 0, attempt.Error != "")
+			}
+			assert.Equal(t, tt.wantAttempts, statuses)
+			assert.Equal(t, tt.wantPassed, result.Passed)
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+
+	tests := []struct {
+		retries  int
+		min, max time.Duration
+	}{
+		{retries: 0, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{retries: 1, min: 100 * time.Millisecond, max: 200 * time.Millisecond},
+		{retries: 2, min: 150 * time.Millisecond, max: 300 * time.Millisecond},
+		{retries: 10, min: 150 * time.Millisecond, max: 300 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("Retry %d", tt.retries+1), func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				wait := policy.backoff(tt.retries)
+				assert.GreaterOrEqual(t, wait, tt.min)
+				assert.LessOrEqual(t, wait, tt.max)
+			}
+		})
+	}
+}
+
+func TestExecuteCaseSecurity(t *testing.T) {
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			atomic.AddInt32(&fetches, 1)
+			id, secret, _ := r.BasicAuth()
+			assert.Equal(t, "client", id)
+			assert.Equal(t, "s3cret", secret)
+			assert.NoError(t, r.ParseForm())
+			if r.PostForm.Get("grant_type") == "password" {
+				assert.Equal(t, "alice", r.PostForm.Get("username"))
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token": r.PostForm.Get("grant_type") + ":" + r.PostForm.Get("scope"),
+				"expires_in":   3600,
+			})
+			return
+		}
+
+		received := map[string]string{
+			"authorization": r.Header.Get("Authorization"),
+			"header":        r.Header.Get("X-API-Key"),
+			"query":         r.URL.Query().Get("api_key"),
+		}
+		if cookie := r.Header.Get("Cookie"); cookie != "" {
+			received["cookie"] = cookie
+		}
+		json.NewEncoder(w).Encode(received)
+	}))
+	defer server.Close()
+
+	schemes := map[string]domain.SecurityScheme{
+		"keyHeader": {Type: "apiKey", In: "header", Name: "X-API-Key"},
+		"keyQuery":  {Type: "apiKey", In: "query", Name: "api_key"},
+		"keyCookie": {Type: "apiKey", In: "cookie", Name: "session"},
+		"basic":     {Type: "http", Scheme: "basic"},
+		"bearer":    {Type: "http", Scheme: "bearer"},
+		"oauth": {Type: "oauth2", Flows: &domain.OAuthFlows{
+			ClientCredentials: &domain.OAuthFlow{TokenURL: "/token"},
+			Password:          &domain.OAuthFlow{TokenURL: server.URL + "/token"},
+		}},
+	}
+
+	tests := []struct {
+		name        string
+		global      []domain.SecurityRequirement
+		operation   []domain.SecurityRequirement
+		credentials map[string]Credential
+		env         map[string]string
+		want        map[string]string
+		wantLogged  map[string]string
+		wantURL     string
+		wantFetches int32
+	}{
+		{
+			name:        "API key in a header",
+			global:      []domain.SecurityRequirement{{"keyHeader": nil}},
+			credentials: map[string]Credential{"keyHeader": {APIKey: "k1"}},
+			want:        map[string]string{"authorization": "", "header": "k1", "query": ""},
+			wantLogged:  map[string]string{"X-Api-Key": "REDACTED"},
+		},
+		{
+			name:        "API key in the query and a cookie",
+			global:      []domain.SecurityRequirement{{"keyQuery": nil, "keyCookie": nil}},
+			credentials: map[string]Credential{"keyQuery": {APIKey: "k2"}, "keyCookie": {APIKey: "c1"}},
+			want:        map[string]string{"authorization": "", "header": "", "query": "k2", "cookie": "session=c1"},
+			wantLogged:  map[string]string{"Cookie": "session=REDACTED"},
+			wantURL:     "/pets?api_key=REDACTED",
+		},
+		{
+			name:        "Cookie API key sent unquoted",
+			global:      []domain.SecurityRequirement{{"keyCookie": nil}},
+			credentials: map[string]Credential{"keyCookie": {APIKey: "se cret,val"}},
+			want:        map[string]string{"authorization": "", "header": "", "query": "", "cookie": "session=se cret,val"},
+			wantLogged:  map[string]string{"Cookie": "session=REDACTED"},
+		},
+		{
+			name:       "Basic credentials from the environment",
+			global:     []domain.SecurityRequirement{{"basic": nil}},
+			env:        map[string]string{"SPECDRILL_BASIC_USERNAME": "alice", "SPECDRILL_BASIC_PASSWORD": "pw"},
+			want:       map[string]string{"authorization": "Basic YWxpY2U6cHc=", "header": "", "query": ""},
+			wantLogged: map[string]string{"Authorization": "Basic REDACTED"},
+		},
+		{
+			name:        "Environment overrides the secrets file",
+			global:      []domain.SecurityRequirement{{"bearer": nil}},
+			credentials: map[string]Credential{"bearer": {Token: "file"}},
+			env:         map[string]string{"SPECDRILL_BEARER_TOKEN": "env"},
+			want:        map[string]string{"authorization": "Bearer env", "header": "", "query": ""},
+			wantLogged:  map[string]string{"Authorization": "Bearer REDACTED"},
+		},
+		{
+			name:        "First satisfiable alternative",
+			global:      []domain.SecurityRequirement{{"basic": nil}, {"keyHeader": nil}},
+			credentials: map[string]Credential{"keyHeader": {APIKey: "k1"}},
+			want:        map[string]string{"authorization": "", "header": "k1", "query": ""},
+		},
+		{
+			name:        "Operation opts out of global security",
+			global:      []domain.SecurityRequirement{{"keyHeader": nil}},
+			operation:   []domain.SecurityRequirement{},
+			credentials: map[string]Credential{"keyHeader": {APIKey: "k1"}},
+			want:        map[string]string{"authorization": "", "header": "", "query": ""},
+		},
+		{
+			name:   "No credentials",
+			global: []domain.SecurityRequirement{{"bearer": nil}},
+			want:   map[string]string{"authorization": "", "header": "", "query": ""},
+		},
+		{
+			name:        "OAuth2 client credentials fetched once",
+			operation:   []domain.SecurityRequirement{{"oauth": {"read", "write"}}},
+			credentials: map[string]Credential{"oauth": {ClientID: "client", ClientSecret: "s3cret"}},
+			want:        map[string]string{"authorization": "Bearer client_credentials:read write", "header": "", "query": ""},
+			wantLogged:  map[string]string{"Authorization": "Bearer REDACTED"},
+			wantFetches: 1,
+		},
+		{
+			name:        "OAuth2 password flow",
+			operation:   []domain.SecurityRequirement{{"oauth": nil}},
+			credentials: map[string]Credential{"oauth": {ClientID: "client", ClientSecret: "s3cret", Username: "alice", Password: "pw"}},
+			want:        map[string]string{"authorization": "Bearer password:", "header": "", "query": ""},
+			wantFetches: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			atomic.StoreInt32(&fetches, 0)
+
+			spec := &domain.APISpec{
+				BaseURL:    server.URL,
+				Security:   tt.global,
+				Components: domain.Components{SecuritySchemes: schemes},
+				Paths: map[string]domain.PathItem{
+					"/pets": {Get: &domain.Operation{Security: tt.operation}},
+				},
+			}
+			executor := NewExecutor(generator.NewGenerator(generator.Options{}), validator.NewValidator(), Options{Credentials: tt.credentials})
+			testCase := model.TestCase{Name: "GET /pets", Method: "GET", Path: "/pets", ExpectedStatuses: []string{"200"}}
+
+			for i := 0; i < 2; i++ {
+				result, err := executor.ExecuteCase(context.Background(), spec, testCase)
+				assert.NoError(t, err)
+
+				var received map[string]string
+				assert.NoError(t, json.Unmarshal([]byte(result.Body), &received))
+				assert.Equal(t, tt.want, received)
+				for header, value := range tt.wantLogged {
+					assert.Equal(t, value, result.Request.RequestHeaders[header])
+				}
+				if tt.wantURL != "" {
+					assert.Equal(t, server.URL+tt.wantURL, result.Request.URL)
+				}
+			}
+			assert.Equal(t, tt.wantFetches, atomic.LoadInt32(&fetches))
+		})
+	}
+}
+
+func TestExecuteCaseRedactsErrors(t *testing.T) {
+	// Nothing listens once the server is closed, so the request fails with
+	// an error quoting its URL
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	spec := &domain.APISpec{
+		BaseURL:  server.URL,
+		Security: []domain.SecurityRequirement{{"keyQuery": nil}},
+		Components: domain.Components{SecuritySchemes: map[string]domain.SecurityScheme{
+			"keyQuery": {Type: "apiKey", In: "query", Name: "api_key"},
+		}},
+		Paths: map[string]domain.PathItem{"/pets": {Get: &domain.Operation{}}},
+	}
+	executor := NewExecutor(generator.NewGenerator(generator.Options{}), validator.NewValidator(), Options{
+		Credentials: map[string]Credential{"keyQuery": {APIKey: "TOP SECRET"}},
+		Retry:       RetryPolicy{MaxAttempts: 2},
+	})
+
+	result, err := executor.ExecuteCase(context.Background(), spec, model.TestCase{Name: "GET /pets", Method: "GET", Path: "/pets"})
+	if !assert.Error(t, err) {
+		return
+	}
+	assert.Contains(t, err.Error(), "api_key=REDACTED")
+
+	logged := []string{err.Error(), result.Error.Error(), result.Request.Error, result.Request.URL, result.Request.Curl()}
+	assert.Len(t, result.Attempts, 2)
+	for _, attempt := range result.Attempts {
+		logged = append(logged, attempt.Error)
+	}
+	for _, s := range logged {
+		assert.NotContains(t, s, "SECRET")
+	}
+}
+
+func TestExecuteCaseRejectsUnsafeCookie(t *testing.T) {
+	spec := &domain.APISpec{
+		BaseURL:  "http://localhost",
+		Security: []domain.SecurityRequirement{{"keyCookie": nil}},
+		Components: domain.Components{SecuritySchemes: map[string]domain.SecurityScheme{
+			"keyCookie": {Type: "apiKey", In: "cookie", Name: "session"},
+		}},
+		Paths: map[string]domain.PathItem{"/pets": {Get: &domain.Operation{}}},
+	}
+	executor := NewExecutor(generator.NewGenerator(generator.Options{}), validator.NewValidator(), Options{
+		Credentials: map[string]Credential{"keyCookie": {APIKey: "a;b"}},
+	})
+
+	_, err := executor.ExecuteCase(context.Background(), spec, model.TestCase{Name: "GET /pets", Method: "GET", Path: "/pets"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "cannot carry")
+		assert.NotContains(t, err.Error(), "a;b")
+	}
+}
+
+func TestExecuteCaseRedactsRetriedErrors(t *testing.T) {
+	// The first connection is dropped without a response; the retry succeeds
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	spec := &domain.APISpec{
+		BaseURL:  server.URL,
+		Security: []domain.SecurityRequirement{{"keyQuery": nil}},
+		Components: domain.Components{SecuritySchemes: map[string]domain.SecurityScheme{
+			"keyQuery": {Type: "apiKey", In: "query", Name: "api_key"},
+		}},
+		Paths: map[string]domain.PathItem{"/pets": {Get: &domain.Operation{}}},
+	}
+	executor := NewExecutor(generator.NewGenerator(generator.Options{}), validator.NewValidator(), Options{
+		Credentials: map[string]Credential{"keyQuery": {APIKey: "TOPSECRET"}},
+		Retry:       RetryPolicy{MaxAttempts: 2},
+	})
+
+	result, err := executor.ExecuteCase(context.Background(), spec, model.TestCase{Name: "GET /pets", Method: "GET", Path: "/pets"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, http.StatusNoContent, result.StatusCode)
+	if assert.Len(t, result.Attempts, 2) {
+		assert.NotEmpty(t, result.Attempts[0].Error)
+		assert.Contains(t, result.Attempts[0].Error, "api_key=REDACTED")
+		assert.NotContains(t, result.Attempts[0].Error, "TOPSECRET")
+	}
+}
+
+func TestTokenCacheConcurrent(t *testing.T) {
+	release := make(chan struct{})
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		if r.FormValue("scope") == "slow" {
+			<-release
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": r.FormValue("scope")})
+	}))
+	defer server.Close()
+	defer close(release)
+
+	var cache tokenCache
+	request := func(scope string) tokenRequest {
+		return tokenRequest{url: server.URL, grant: "client_credentials", scopes: []string{scope}}
+	}
+
+	slow := make(chan string, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			token, _ := cache.token(context.Background(), server.Client(), request("slow"))
+			slow <- token
+		}()
+	}
+
+	for atomic.LoadInt32(&fetches) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// An unrelated token is fetched while the slow one is still pending
+	done := make(chan string)
+	go func() {
+		token, _ := cache.token(context.Background(), server.Client(), request("fast"))
+		done <- token
+	}()
+	select {
+	case token := <-done:
+		assert.Equal(t, "fast", token)
+	case <-time.After(2 * time.Second):
+		t.Fatal("fetching one token blocked another")
+	}
+
+	// Both requests for the slow token share a single fetch
+	release <- struct{}{}
+	assert.Equal(t, "slow", <-slow)
+	assert.Equal(t, "slow", <-slow)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+}
+
+func TestExecuteCaseImported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			json.NewEncoder(w).Encode(map[string]string{"contentType": r.Header.Get("Content-Type"), "body": string(body)})
+			return
+		}
+		w.Write([]byte(`{"id": "seven"}`))
+	}))
+	defer server.Close()
+
+	pets := domain.Response{Content: map[string]domain.MediaType{
+		"application/json": {Schema: domain.Schema{
+			Type:       "object",
+			Properties: map[string]domain.Schema{"id": {Type: "integer"}},
+		}},
+	}}
+	spec := &domain.APISpec{
+		BaseURL: server.URL,
+		Paths: map[string]domain.PathItem{
+			"/pets": {
+				Get:  &domain.Operation{Responses: map[string]domain.Response{"200": pets, "404": {}}},
+				Post: &domain.Operation{Responses: map[string]domain.Response{"201": {}, "200": {}}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		drift          bool
+		testCase       model.TestCase
+		wantExpected   []string
+		wantViolations []model.Violation
+		wantBody       string
+	}{
+		{
+			name:           "Statuses filled in from the spec",
+			testCase:       model.TestCase{Name: "GET /pets", Method: "GET", Path: "/pets"},
+			wantExpected:   []string{"200"},
+			wantViolations: []model.Violation{{Pointer: "/id", Keyword: "type", Expected: "integer", Actual: "string"}},
+		},
+		{
+			name:           "Documented status is not drift",
+			drift:          true,
+			testCase:       model.TestCase{Name: "GET /pets", Method: "GET", Path: "/pets", ExpectedStatuses: []string{"200"}},
+			wantExpected:   []string{"200"},
+			wantViolations: []model.Violation{{Pointer: "/id", Keyword: "type", Expected: "integer", Actual: "string"}},
+		},
+		{
+			name:         "Body without a content type is sent as JSON",
+			testCase:     model.TestCase{Name: "POST /pets", Method: "POST", Path: "/pets", RequestBody: map[string]interface{}{"name": "Rex"}},
+			wantExpected: []string{"200", "201"},
+			wantBody:     `{"body":"{\"name\":\"Rex\"}","contentType":"application/json"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := NewExecutor(generator.NewGenerator(generator.Options{}), validator.NewValidator(), Options{Drift: tt.drift})
+			result, err := executor.ExecuteCase(context.Background(), spec, tt.testCase)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, tt.wantExpected, result.TestCase.ExpectedStatuses)
+			assert.Equal(t, "200", result.MatchedStatus)
+			assert.Equal(t, tt.wantViolations, result.Violations)
+			assert.Empty(t, result.Drift)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, result.Body)
+			}
+		})
+	}
+}
+
+func TestExecuteCaseSwagger2Form(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	swagger := `swagger: "2.0"
+info:
+  title: Forms
+  version: 1.0.0
+paths:
+  /forms:
+    post:
+      consumes: [application/x-www-form-urlencoded]
+      parameters:
+        - {name: csv, in: formData, type: array, items: {type: string}}
+        - {name: ssv, in: formData, type: array, items: {type: string}, collectionFormat: ssv}
+        - {name: pipes, in: formData, type: array, items: {type: string}, collectionFormat: pipes}
+        - {name: tsv, in: formData, type: array, items: {type: string}, collectionFormat: tsv}
+        - {name: multi, in: formData, type: array, items: {type: string}, collectionFormat: multi}
+      responses:
+        '204':
+          description: Stored
+  /uploads:
+    post:
+      consumes: [multipart/form-data]
+      parameters:
+        - {name: tags, in: formData, type: array, items: {type: string}}
+      responses:
+        '204':
+          description: Stored
+`
+	specPath := filepath.Join(t.TempDir(), "swagger.yaml")
+	if err := os.WriteFile(specPath, []byte(swagger), 0644); err != nil {
+		t.Fatalf("Failed to write test spec: %v", err)
+	}
+	spec, err := parser.NewParser(parser.Options{}).ParseSpec(context.Background(), specPath, server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.ElementsMatch(t, []string{
+		`post /forms: parameter "tsv" uses collectionFormat tsv, which has no OpenAPI 3 equivalent; it is sent comma separated`,
+		`post /uploads: parameter "tags" uses collectionFormat csv in a multipart/form-data body; it is sent as repeated fields`,
+	}, spec.Warnings)
+
+	items := []interface{}{"a", "b"}
+	executor := NewExecutor(generator.NewGenerator(generator.Options{}), validator.NewValidator(), Options{})
+	_, err = executor.ExecuteCase(context.Background(), spec, model.TestCase{
+		Name:        "POST /forms",
+		Method:      "POST",
+		Path:        "/forms",
+		ContentType: "application/x-www-form-urlencoded",
+		RequestBody: map[string]interface{}{"csv": items, "ssv": items, "pipes": items, "tsv": items, "multi": items},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "csv=a%2Cb&multi=a&multi=b&pipes=a%7Cb&ssv=a+b&tsv=a%2Cb", body)
+}
+
+func TestExecuteCaseSwagger2BasePath(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	swagger := `swagger: "2.0"
+info:
+  title: Pets
+  version: 1.0.0
+basePath: /api/v1
+paths:
+  /pets/{id}:
+    delete:
+      parameters:
+        - {name: id, in: path, required: true, type: integer}
+      responses:
+        '204':
+          description: Deleted
+`
+	specPath := filepath.Join(t.TempDir(), "swagger.yaml")
+	if err := os.WriteFile(specPath, []byte(swagger), 0644); err != nil {
+		t.Fatalf("Failed to write test spec: %v", err)
+	}
+
+	// The basePath is added once, whether or not the base URL has it
+	for _, baseURL := range []string{server.URL, server.URL + "/api/v1/"} {
+		spec, err := parser.NewParser(parser.Options{}).ParseSpec(context.Background(), specPath, baseURL)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		executor := NewExecutor(generator.NewGenerator(generator.Options{}), validator.NewValidator(), Options{})
+		result, err := executor.ExecuteTest(context.Background(), spec, "/pets/{id}", "DELETE")
+		assert.NoError(t, err)
+		assert.True(t, result.Passed)
+		assert.Equal(t, "/api/v1/pets/1", path)
+	}
+}
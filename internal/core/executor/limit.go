@@ -0,0 +1,40 @@
+package executor
+
+import (
+	"context"
+	"sync"
+)
+
+// hostLimiter caps the requests in flight to each host
+type hostLimiter struct {
+	mu    sync.Mutex
+	limit int
+	slots map[string]chan struct{}
+}
+
+// acquire blocks until a request to host may be sent, or until ctx is
+// done, and returns the function that frees its slot. Without a limit it
+// never blocks.
+func (l *hostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	if l.limit <= 0 {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	if l.slots == nil {
+		l.slots = make(map[string]chan struct{})
+	}
+	slots, ok := l.slots[host]
+	if !ok {
+		slots = make(chan struct{}, l.limit)
+		l.slots[host] = slots
+	}
+	l.mu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
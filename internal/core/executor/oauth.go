@@ -0,0 +1,138 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenExpiryMargin is how long before its expiry a cached token is
+// replaced, so it does not run out while a request is in flight
+const tokenExpiryMargin = 30 * time.Second
+
+// tokenRequest identifies an access token to fetch from an OAuth2 token
+// endpoint
+type tokenRequest struct {
+	url        string
+	grant      string // client_credentials or password
+	scopes     []string
+	credential Credential
+}
+
+// cachedToken is an access token and when it stops being used; a zero
+// expiry means it is kept for the whole run
+type cachedToken struct {
+	value   string
+	expires time.Time
+}
+
+// tokenCache fetches OAuth2 access tokens and reuses them until shortly
+// before they expire. It is safe for concurrent use: requests for the same
+// token wait for a single fetch, while other tokens are unaffected.
+type tokenCache struct {
+	mu      sync.Mutex
+	entries map[string]*tokenEntry
+}
+
+// tokenEntry holds one token. Its lock is held while the token is fetched,
+// so concurrent requests for it share the fetch.
+type tokenEntry struct {
+	mu     sync.Mutex
+	cached cachedToken
+}
+
+// token returns a cached access token, fetching a new one when there is
+// none or it is about to expire
+func (c *tokenCache) token(ctx context.Context, client *http.Client, r tokenRequest) (string, error) {
+	key := strings.Join([]string{r.url, r.grant, r.credential.ClientID, r.credential.Username, strings.Join(r.scopes, " ")}, "\x00")
+
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[string]*tokenEntry)
+	}
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &tokenEntry{}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	cached := entry.cached
+	if cached.value != "" && (cached.expires.IsZero() || time.Now().Before(cached.expires)) {
+		return cached.value, nil
+	}
+
+	cached, err := fetchToken(ctx, client, r)
+	if err != nil {
+		return "", err
+	}
+	entry.cached = cached
+	return cached.value, nil
+}
+
+// fetchToken requests an access token, authenticating the client with HTTP
+// basic as RFC 6749 recommends
+func fetchToken(ctx context.Context, client *http.Client, r tokenRequest) (cachedToken, error) {
+	form := url.Values{"grant_type": {r.grant}}
+	if len(r.scopes) > 0 {
+		form.Set("scope", strings.Join(r.scopes, " "))
+	}
+	if r.grant == "password" {
+		form.Set("username", r.credential.Username)
+		form.Set("password", r.credential.Password)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return cachedToken{}, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if r.credential.ClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(r.credential.ClientID), url.QueryEscape(r.credential.ClientSecret))
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return cachedToken{}, fmt.Errorf("failed to fetch token: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return cachedToken{}, fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return cachedToken{}, fmt.Errorf("failed to fetch token: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var payload struct {
+		AccessToken string      `json:"access_token"`
+		ExpiresIn   json.Number `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return cachedToken{}, fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if payload.AccessToken == "" {
+		return cachedToken{}, fmt.Errorf("token response has no access_token")
+	}
+
+	cached := cachedToken{value: payload.AccessToken}
+	if seconds, err := payload.ExpiresIn.Int64(); err == nil && seconds > 0 {
+		lifetime := time.Duration(seconds) * time.Second
+		margin := tokenExpiryMargin
+		if margin > lifetime/2 {
+			margin = lifetime / 2
+		}
+		cached.expires = start.Add(lifetime - margin)
+	}
+	return cached, nil
+}
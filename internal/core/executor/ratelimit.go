@@ -0,0 +1,82 @@
+package executor
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// tokenBucket paces requests to a steady rate, allowing bursts of up to
+// one second's worth
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // Tokens added per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64) *tokenBucket {
+	burst := math.Max(1, rate)
+	return &tokenBucket{rate: rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// reserve takes a token and returns how long to wait before using it.
+// Tokens may be taken ahead of time, so waiting callers are served in
+// order.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// rateLimiter paces requests across the run and per operation
+type rateLimiter struct {
+	global       *tokenBucket
+	perOperation float64
+
+	mu         sync.Mutex
+	operations map[string]*tokenBucket
+}
+
+func newRateLimiter(global, perOperation float64) *rateLimiter {
+	l := &rateLimiter{perOperation: perOperation}
+	if global > 0 {
+		l.global = newTokenBucket(global)
+	}
+	return l
+}
+
+// wait blocks until a request to operation may be sent under both limits,
+// or until ctx is done
+func (l *rateLimiter) wait(ctx context.Context, operation string) error {
+	var delay time.Duration
+	if l.perOperation > 0 {
+		l.mu.Lock()
+		if l.operations == nil {
+			l.operations = make(map[string]*tokenBucket)
+		}
+		bucket, ok := l.operations[operation]
+		if !ok {
+			bucket = newTokenBucket(l.perOperation)
+			l.operations[operation] = bucket
+		}
+		l.mu.Unlock()
+		delay = bucket.reserve()
+	}
+	if l.global != nil {
+		if global := l.global.reserve(); global > delay {
+			delay = global
+		}
+	}
+	return sleep(ctx, delay)
+}
@@ -0,0 +1,66 @@
+package executor
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// idempotentMethods can be sent again without changing the outcome
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+	http.MethodTrace:   true,
+}
+
+// RetryPolicy controls retries of transient failures: connection errors
+// and the listed response statuses. The wait before each retry doubles from
+// BaseDelay up to MaxDelay, with random jitter so concurrent cases spread
+// out.
+type RetryPolicy struct {
+	// MaxAttempts is the most tries per case, including the first; values
+	// below 2 disable retries
+	MaxAttempts int
+	// Statuses are the response codes treated as transient, e.g. 502
+	Statuses  []int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// AllMethods also retries methods that are not idempotent, such as
+	// POST and PATCH
+	AllMethods bool
+}
+
+// allows reports whether a request that has been retried retries times may
+// be retried again
+func (p RetryPolicy) allows(method string, retries int) bool {
+	return retries+1 < p.MaxAttempts && (p.AllMethods || idempotentMethods[method])
+}
+
+func (p RetryPolicy) retriesStatus(status int) bool {
+	for _, candidate := range p.Statuses {
+		if candidate == status {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the wait before retry number retries+1: half the
+// exponential delay plus a random share of the other half
+func (p RetryPolicy) backoff(retries int) time.Duration {
+	delay := p.BaseDelay
+	for i := 0; i < retries && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
@@ -0,0 +1,87 @@
+package executor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/BarneyRubble12/specdrill/internal/core/domain"
+	"github.com/BarneyRubble12/specdrill/internal/core/model"
+)
+
+// RunSuite executes every case of a suite and summarises the results. Up to
+// Options.Workers cases run at once, but results keep the order of the
+// suite's cases. A case whose request could not be sent fails with its
+// error. The suite's base URL, when set, takes precedence over the spec's.
+//
+// Once ctx is done no further cases are started and those in flight are
+// cancelled; the summary covers the cases that completed and counts the
+// rest as skipped.
+func (e *Executor) RunSuite(ctx context.Context, spec *domain.APISpec, suite model.TestSuite) model.TestSummary {
+	if suite.BaseURL != "" && suite.BaseURL != spec.BaseURL {
+		target := *spec
+		target.BaseURL = suite.BaseURL
+		spec = &target
+	}
+
+	workers := e.options.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(suite.TestCases) {
+		workers = len(suite.TestCases)
+	}
+
+	start := time.Now()
+	results := make([]*model.TestResult, len(suite.TestCases))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				testCase := suite.TestCases[i]
+				result, err := e.ExecuteCase(ctx, spec, testCase)
+				switch {
+				case err != nil && ctx.Err() != nil:
+					// Cancelled along with the run
+				case err != nil && result == nil:
+					results[i] = &model.TestResult{TestCase: testCase, Error: err}
+				default:
+					results[i] = result
+				}
+			}
+		}()
+	}
+dispatch:
+	for i := range suite.TestCases {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	summary := model.TestSummary{
+		Duration:    time.Since(start).Milliseconds(),
+		Results:     make([]model.TestResult, 0, len(results)),
+		Interrupted: ctx.Err() != nil,
+	}
+	for _, result := range results {
+		if result == nil {
+			summary.Skipped++
+			continue
+		}
+		summary.TotalTests++
+		if result.Passed {
+			summary.PassedTests++
+		} else {
+			summary.FailedTests++
+		}
+		summary.Results = append(summary.Results, *result)
+	}
+	return summary
+}
@@ -0,0 +1,124 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BarneyRubble12/specdrill/internal/core/model"
+)
+
+// maxThrottleRetries bounds retries of a throttled request however short
+// the Retry-After
+const maxThrottleRetries = 10
+
+// send sends a request, paced by the rate limits, and sends it again while
+// the API answers 429 or 503 with a Retry-After that fits the remaining
+// retry budget, or fails in a way the retry policy deems transient. Every
+// try is recorded as an attempt. The response body is returned already
+// read. Waiting stops when the request's context is done.
+func (e *Executor) send(req *http.Request, body []byte, operation string) (*http.Response, []byte, []model.Attempt, error) {
+	ctx := req.Context()
+	policy := e.options.Retry
+	var attempts []model.Attempt
+	var waited time.Duration
+	retries := 0
+	for {
+		if err := e.limiter.wait(ctx, operation); err != nil {
+			return nil, nil, attempts, err
+		}
+
+		attemptReq := req.Clone(ctx)
+		if body != nil {
+			attemptReq.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		release, err := e.hosts.acquire(ctx, req.URL.Host)
+		if err != nil {
+			return nil, nil, attempts, err
+		}
+		start := time.Now()
+		resp, err := e.client.Do(attemptReq)
+		var respBody []byte
+		if err == nil {
+			respBody, err = io.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+		release()
+
+		attempt := model.Attempt{Duration: time.Since(start).Milliseconds()}
+		var wait time.Duration
+		switch {
+		case err != nil:
+			attempt.Error = err.Error()
+			if ctx.Err() != nil || !policy.allows(req.Method, retries) {
+				return nil, nil, append(attempts, attempt), err
+			}
+			wait = policy.backoff(retries)
+			retries++
+		default:
+			attempt.StatusCode = resp.StatusCode
+			throttled, ok := retryAfter(resp, time.Now())
+			switch {
+			case ok && e.options.RetryBudget > 0 && waited+throttled <= e.options.RetryBudget && len(attempts) < maxThrottleRetries:
+				wait = throttled
+				waited += throttled
+			case policy.retriesStatus(resp.StatusCode) && policy.allows(req.Method, retries):
+				wait = policy.backoff(retries)
+				retries++
+			default:
+				return resp, respBody, append(attempts, attempt), nil
+			}
+		}
+
+		attempt.Wait = wait.Milliseconds()
+		attempts = append(attempts, attempt)
+		if err := sleep(ctx, wait); err != nil {
+			return nil, nil, attempts, err
+		}
+	}
+}
+
+// retryAfter returns how long a 429 or 503 response asks the client to
+// wait before retrying. Retry-After holds either seconds or an HTTP date.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// sleep waits for d, or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,231 @@
+package fuzzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BarneyRubble12/specdrill/internal/core/domain"
+	"github.com/BarneyRubble12/specdrill/internal/core/executor"
+	"github.com/BarneyRubble12/specdrill/internal/core/generator"
+	"github.com/BarneyRubble12/specdrill/internal/core/model"
+)
+
+// maxMutations is the most mutations applied to a single case
+const maxMutations = 3
+
+// Options bounds a fuzzing run
+type Options struct {
+	// Seed determines every generated case; the same seed and spec always
+	// produce the same cases
+	Seed int64
+	// Iterations is the number of cases to send; zero means no limit, in
+	// which case Duration must be set
+	Iterations int
+	// Duration stops the run once exceeded; zero means no time budget
+	Duration time.Duration
+	// Cases replays only the listed case indexes
+	Cases []int
+}
+
+// Finding is a fuzz case the API answered with a 5xx or not at all
+type Finding struct {
+	Seed       int64
+	Index      int
+	Case       model.TestCase
+	StatusCode int   // Zero when the request timed out
+	Err        error // Set when the request timed out
+}
+
+// Report summarises a fuzzing run
+type Report struct {
+	Seed        int64
+	Cases       int
+	Findings    []Finding
+	Interrupted bool // The run stopped early because its context was done
+}
+
+// operation identifies one operation of the spec
+type operation struct {
+	path   string
+	method string
+}
+
+// Fuzzer sends mutated versions of schema-valid requests and flags server
+// errors and timeouts
+type Fuzzer struct {
+	generator *generator.Generator
+	executor  *executor.Executor
+}
+
+// NewFuzzer creates a new Fuzzer instance
+func NewFuzzer(generator *generator.Generator, executor *executor.Executor) *Fuzzer {
+	return &Fuzzer{
+		generator: generator,
+		executor:  executor,
+	}
+}
+
+// Run sends fuzz cases until the iteration or time budget is spent, or
+// replays the requested cases. A run without any budget sends nothing. The
+// run stops when ctx is done, reporting the cases completed so far.
+func (f *Fuzzer) Run(ctx context.Context, spec *domain.APISpec, options Options) Report {
+	report := Report{Seed: options.Seed}
+	defer func() {
+		report.Interrupted = ctx.Err() != nil
+	}()
+	if len(options.Cases) > 0 {
+		for _, index := range options.Cases {
+			if !f.runCase(ctx, spec, index, &report) {
+				break
+			}
+		}
+		return report
+	}
+	if options.Iterations <= 0 && options.Duration <= 0 {
+		return report
+	}
+
+	start := time.Now()
+	for i := 0; options.Iterations <= 0 || i < options.Iterations; i++ {
+		if options.Duration > 0 && time.Since(start) >= options.Duration {
+			break
+		}
+		if !f.runCase(ctx, spec, i, &report) {
+			break
+		}
+	}
+	return report
+}
+
+// runCase sends one fuzz case, recording it as a finding if the API failed
+// with a 5xx or timed out. It reports false when there is nothing to fuzz or
+// ctx is done.
+func (f *Fuzzer) runCase(ctx context.Context, spec *domain.APISpec, index int, report *Report) bool {
+	testCase, ok := f.Case(spec, report.Seed, index)
+	if !ok || ctx.Err() != nil {
+		return false
+	}
+
+	finding := Finding{Seed: report.Seed, Index: index, Case: testCase}
+	result, err := f.executor.ExecuteCase(ctx, spec, testCase)
+	if ctx.Err() != nil {
+		// Cancelled with the run, so not the API's fault
+		return false
+	}
+	report.Cases++
+	var netErr net.Error
+	switch {
+	case errors.As(err, &netErr) && netErr.Timeout():
+		finding.Err = err
+	case err == nil && result.StatusCode >= 500:
+		finding.StatusCode = result.StatusCode
+	default:
+		return true
+	}
+	report.Findings = append(report.Findings, finding)
+	return true
+}
+
+// Case builds fuzz case index of the run with the given seed. The case
+// depends only on the spec, seed and index, so any case can be replayed on
+// its own. It reports false when the spec has no operations.
+func (f *Fuzzer) Case(spec *domain.APISpec, seed int64, index int) (model.TestCase, bool) {
+	operations := listOperations(spec)
+	if len(operations) == 0 {
+		return model.TestCase{}, false
+	}
+	op := operations[index%len(operations)]
+	r := rand.New(rand.NewSource(caseSeed(seed, index)))
+
+	testCase := f.generator.CreateTestCase(op.method, op.path, spec.Paths[op.path])
+	testCase.ExpectedStatuses = tolerableStatuses(testCase.DocumentedStatuses)
+	testCase.Params = append([]model.Param(nil), testCase.Params...)
+
+	var applied []string
+	for n := 1 + r.Intn(maxMutations); n > 0; n-- {
+		m := mutators[r.Intn(len(mutators))]
+		targets := len(testCase.Params)
+		var pointers []string
+		if testCase.ContentType != "" {
+			pointers = bodyTargets(testCase.RequestBody, "")
+			targets += len(pointers)
+		}
+		if targets == 0 {
+			break
+		}
+
+		target := r.Intn(targets)
+		if target < len(testCase.Params) {
+			param := &testCase.Params[target]
+			param.Value = m.apply(r, param.Value)
+			applied = append(applied, fmt.Sprintf("%s at %s parameter %s", m.name, param.In, param.Name))
+			continue
+		}
+		pointer := pointers[target-len(testCase.Params)]
+		testCase.RequestBody = replaceAt(testCase.RequestBody, pointer, func(v interface{}) interface{} {
+			return m.apply(r, v)
+		})
+		applied = append(applied, fmt.Sprintf("%s at body %s", m.name, rootPointer(pointer)))
+	}
+
+	testCase.Description = fmt.Sprintf("fuzz #%d", index)
+	if len(applied) > 0 {
+		testCase.Description += " (" + strings.Join(applied, "; ") + ")"
+	}
+	testCase.Name = fmt.Sprintf("%s %s: %s", op.method, op.path, testCase.Description)
+	return testCase, true
+}
+
+// listOperations returns the spec's operations in a stable order
+func listOperations(spec *domain.APISpec) []operation {
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var operations []operation
+	for _, path := range paths {
+		for _, method := range domain.Methods {
+			if spec.Paths[path].Operation(method) != nil {
+				operations = append(operations, operation{path: path, method: method})
+			}
+		}
+	}
+	return operations
+}
+
+// caseSeed derives an independent random source seed for each case using
+// the splitmix64 finaliser
+func caseSeed(seed int64, index int) int64 {
+	z := uint64(seed) + uint64(index+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
+
+// tolerableStatuses lists the responses a fuzz case may get without
+// indicating a fault: any success or client error
+func tolerableStatuses(documented []string) []string {
+	var statuses []string
+	for _, status := range documented {
+		ranged := strings.EqualFold(status, "2XX") || strings.EqualFold(status, "4XX")
+		if (strings.HasPrefix(status, "2") || strings.HasPrefix(status, "4")) && !ranged {
+			statuses = append(statuses, status)
+		}
+	}
+	return append(statuses, "2XX", "4XX")
+}
+
+func rootPointer(pointer string) string {
+	if pointer == "" {
+		return "(root)"
+	}
+	return pointer
+}
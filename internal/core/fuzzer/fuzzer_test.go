@@ -0,0 +1,230 @@
+package fuzzer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BarneyRubble12/specdrill/internal/core/domain"
+	"github.com/BarneyRubble12/specdrill/internal/core/executor"
+	"github.com/BarneyRubble12/specdrill/internal/core/generator"
+	"github.com/BarneyRubble12/specdrill/internal/core/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func testSpec(baseURL string) *domain.APISpec {
+	return &domain.APISpec{
+		BaseURL: baseURL,
+		Paths: map[string]domain.PathItem{
+			"/pets": {
+				Get: &domain.Operation{
+					Parameters: []domain.Parameter{
+						{Name: "limit", In: "query", Required: true, Schema: domain.Schema{Type: "integer"}},
+					},
+					Responses: map[string]domain.Response{"200": {}},
+				},
+				Post: &domain.Operation{
+					RequestBody: &domain.RequestBody{Content: map[string]domain.MediaType{
+						"application/json": {Example: map[string]interface{}{
+							"name": "Rex",
+							"tags": []interface{}{"good", "boy"},
+						}},
+					}},
+					Responses: map[string]domain.Response{"201": {}, "400": {}},
+				},
+			},
+		},
+	}
+}
+
+func newFuzzer(options executor.Options) *Fuzzer {
+	gen := generator.NewGenerator(generator.Options{})
+	return NewFuzzer(gen, executor.NewExecutor(gen, validator.NewValidator(), options))
+}
+
+func TestCase(t *testing.T) {
+	f := newFuzzer(executor.Options{})
+	spec := testSpec("")
+
+	tests := []struct {
+		name  string
+		check func(*testing.T)
+	}{
+		{
+			name: "Same seed and index give the same case",
+			check: func(t *testing.T) {
+				for index := 0; index < 50; index++ {
+					first, ok := f.Case(spec, 42, index)
+					assert.True(t, ok)
+					second, _ := f.Case(spec, 42, index)
+					assert.Equal(t, first, second)
+				}
+			},
+		},
+		{
+			name: "Different seeds give different cases",
+			check: func(t *testing.T) {
+				differ := false
+				for index := 0; index < 20; index++ {
+					a, _ := f.Case(spec, 1, index)
+					b, _ := f.Case(spec, 2, index)
+					differ = differ || a.Name != b.Name
+				}
+				assert.True(t, differ)
+			},
+		},
+		{
+			name: "Operations are cycled in a stable order",
+			check: func(t *testing.T) {
+				for index, method := range []string{"GET", "POST", "GET", "POST"} {
+					testCase, _ := f.Case(spec, 7, index)
+					assert.Equal(t, method, testCase.Method)
+					assert.Equal(t, "/pets", testCase.Path)
+				}
+			},
+		},
+		{
+			name: "Cases are named with their index and mutations",
+			check: func(t *testing.T) {
+				testCase, _ := f.Case(spec, 7, 3)
+				assert.True(t, strings.HasPrefix(testCase.Name, "POST /pets: fuzz #3 ("), testCase.Name)
+				assert.Equal(t, []string{"201", "400", "2XX", "4XX"}, testCase.ExpectedStatuses)
+			},
+		},
+		{
+			name: "Spec without operations",
+			check: func(t *testing.T) {
+				_, ok := f.Case(&domain.APISpec{}, 7, 0)
+				assert.False(t, ok)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.check)
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name         string
+		handler      http.HandlerFunc
+		timeout      time.Duration
+		options      Options
+		wantCases    int
+		wantFindings int
+		wantTimeout  bool
+	}{
+		{
+			name:      "Tolerated responses",
+			handler:   func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusBadRequest) },
+			options:   Options{Seed: 1, Iterations: 10},
+			wantCases: 10,
+		},
+		{
+			name:         "Server errors are findings",
+			handler:      func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusInternalServerError) },
+			options:      Options{Seed: 1, Iterations: 4},
+			wantCases:    4,
+			wantFindings: 4,
+		},
+		{
+			name: "Timeouts are findings",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				io.Copy(io.Discard, r.Body)
+				time.Sleep(200 * time.Millisecond)
+			},
+			timeout:      20 * time.Millisecond,
+			options:      Options{Seed: 1, Iterations: 2},
+			wantCases:    2,
+			wantFindings: 2,
+			wantTimeout:  true,
+		},
+		{
+			name:         "Replay a single case",
+			handler:      func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusBadGateway) },
+			options:      Options{Seed: 1, Iterations: 100, Cases: []int{17}},
+			wantCases:    1,
+			wantFindings: 1,
+		},
+		{
+			name:    "No budget",
+			handler: func(w http.ResponseWriter, r *http.Request) {},
+			options: Options{Seed: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+			spec := testSpec(server.URL)
+			f := newFuzzer(executor.Options{Timeout: tt.timeout})
+
+			report := f.Run(context.Background(), spec, tt.options)
+			assert.Equal(t, tt.options.Seed, report.Seed)
+			assert.Equal(t, tt.wantCases, report.Cases)
+			assert.Len(t, report.Findings, tt.wantFindings)
+			for _, finding := range report.Findings {
+				assert.Equal(t, tt.options.Seed, finding.Seed)
+				replayed, _ := f.Case(spec, finding.Seed, finding.Index)
+				assert.Equal(t, replayed, finding.Case)
+				if tt.wantTimeout {
+					assert.Error(t, finding.Err)
+					assert.Zero(t, finding.StatusCode)
+				} else {
+					assert.NoError(t, finding.Err)
+					assert.GreaterOrEqual(t, finding.StatusCode, 500)
+				}
+			}
+			if len(tt.options.Cases) > 0 && len(report.Findings) > 0 {
+				assert.Equal(t, tt.options.Cases[0], report.Findings[0].Index)
+			}
+		})
+	}
+}
+
+func TestReplaceAt(t *testing.T) {
+	body := map[string]interface{}{
+		"name": "Rex",
+		"a/b":  []interface{}{1.0, 2.0},
+	}
+
+	tests := []struct {
+		name    string
+		pointer string
+		want    interface{}
+	}{
+		{
+			name:    "Root",
+			pointer: "",
+			want:    nil,
+		},
+		{
+			name:    "Property",
+			pointer: "/name",
+			want:    map[string]interface{}{"name": nil, "a/b": []interface{}{1.0, 2.0}},
+		},
+		{
+			name:    "Escaped property and array item",
+			pointer: "/a~1b/1",
+			want:    map[string]interface{}{"name": "Rex", "a/b": []interface{}{1.0, nil}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := replaceAt(body, tt.pointer, func(interface{}) interface{} { return nil })
+			assert.Equal(t, tt.want, got)
+			// The original body is left untouched
+			assert.Equal(t, "Rex", body["name"])
+			assert.Equal(t, []interface{}{1.0, 2.0}, body["a/b"])
+		})
+	}
+
+	assert.Equal(t, []string{"", "/a~1b", "/a~1b/0", "/a~1b/1", "/name"}, bodyTargets(body, ""))
+}
@@ -0,0 +1,169 @@
+package fuzzer
+
+import (
+	"encoding/json"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// unicodeEdgeCases are strings that commonly trip up parsers, collations
+// and storage layers
+var unicodeEdgeCases = []string{
+	"",
+	"\u0000",
+	"nul\u0000byte",
+	"\u202eright-to-left",
+	"\ufeffbyte-order-mark",
+	"e\u0301\u0301\u0301",
+	"\U0001F4A5\U0001F469\u200d\U0001F467",
+	"\uffff\ufffe",
+	"\U0001D518\U0001D52B\U0001D526",
+	strings.Repeat("\u03a9", 4096),
+	"\t\r\n",
+	"%00%ff",
+}
+
+// hugeNumbers sit at or beyond the limits of common numeric types. They are
+// kept as json.Number so they are sent exactly as written.
+var hugeNumbers = []json.Number{
+	"9223372036854775807",
+	"9223372036854775808",
+	"-9223372036854775809",
+	"18446744073709551616",
+	"9007199254740993",
+	"1e308",
+	"-1e308",
+	"1e400",
+	"0.0000000000000000000000000000001",
+}
+
+// mutator replaces a value with one that stresses a different weakness
+type mutator struct {
+	name  string
+	apply func(r *rand.Rand, v interface{}) interface{}
+}
+
+var mutators = []mutator{
+	{"type confusion", confuseType},
+	{"unicode edge case", func(r *rand.Rand, v interface{}) interface{} {
+		return unicodeEdgeCases[r.Intn(len(unicodeEdgeCases))]
+	}},
+	{"huge number", func(r *rand.Rand, v interface{}) interface{} {
+		return hugeNumbers[r.Intn(len(hugeNumbers))]
+	}},
+	{"deep nesting", nest},
+	{"null injection", func(r *rand.Rand, v interface{}) interface{} {
+		return nil
+	}},
+}
+
+// confuseType returns a value of a different JSON type than v
+func confuseType(r *rand.Rand, v interface{}) interface{} {
+	candidates := []interface{}{
+		"confused",
+		float64(r.Intn(1000)),
+		r.Intn(2) == 0,
+		[]interface{}{v},
+		map[string]interface{}{"value": v},
+	}
+	for {
+		candidate := candidates[r.Intn(len(candidates))]
+		if jsonKind(candidate) != jsonKind(v) {
+			return candidate
+		}
+	}
+}
+
+// nest wraps v in hundreds of arrays or objects
+func nest(r *rand.Rand, v interface{}) interface{} {
+	depth := 100 + r.Intn(400)
+	objects := r.Intn(2) == 0
+	for i := 0; i < depth; i++ {
+		if objects {
+			v = map[string]interface{}{"a": v}
+		} else {
+			v = []interface{}{v}
+		}
+	}
+	return v
+}
+
+func jsonKind(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64, int64, int, json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
+
+// bodyTargets lists the JSON pointers of every value in a body, in a
+// deterministic order
+func bodyTargets(v interface{}, pointer string) []string {
+	targets := []string{pointer}
+	switch value := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			targets = append(targets, bodyTargets(value[k], pointer+"/"+escapeToken(k))...)
+		}
+	case []interface{}:
+		for i, item := range value {
+			targets = append(targets, bodyTargets(item, pointer+"/"+strconv.Itoa(i))...)
+		}
+	}
+	return targets
+}
+
+// replaceAt returns a copy of root with the value at pointer replaced by
+// the result of mutate; containers along the way are copied, not modified
+func replaceAt(root interface{}, pointer string, mutate func(interface{}) interface{}) interface{} {
+	if pointer == "" {
+		return mutate(root)
+	}
+	token, rest, _ := strings.Cut(pointer[1:], "/")
+	if rest != "" {
+		rest = "/" + rest
+	}
+	token = unescapeToken(token)
+
+	switch value := root.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			out[k] = v
+		}
+		out[token] = replaceAt(value[token], rest, mutate)
+		return out
+	case []interface{}:
+		i, err := strconv.Atoi(token)
+		if err != nil || i < 0 || i >= len(value) {
+			return root
+		}
+		out := append([]interface{}(nil), value...)
+		out[i] = replaceAt(value[i], rest, mutate)
+		return out
+	}
+	return root
+}
+
+func escapeToken(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+func unescapeToken(token string) string {
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+}
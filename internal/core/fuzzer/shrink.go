@@ -0,0 +1,230 @@
+package fuzzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/BarneyRubble12/specdrill/internal/core/domain"
+	"github.com/BarneyRubble12/specdrill/internal/core/model"
+)
+
+// maxShrinkAttempts bounds the requests spent minimising one failure
+const maxShrinkAttempts = 200
+
+// Shrink minimises a test case the API answers with a 5xx. It removes
+// parameters, body properties and array items, unwraps nested values,
+// shortens strings and simplifies numbers for as long as the API keeps
+// answering with the same status. It returns the smallest failing case with
+// its result, or an error if the case does not fail in the first place.
+// When ctx is done it returns the smallest failing case found so far.
+func (f *Fuzzer) Shrink(ctx context.Context, spec *domain.APISpec, testCase model.TestCase) (model.TestCase, *model.TestResult, error) {
+	attempts := 0
+	status := 0
+	fails := func(candidate model.TestCase) (*model.TestResult, bool) {
+		attempts++
+		result, err := f.executor.ExecuteCase(ctx, spec, candidate)
+		if err != nil || result.StatusCode < 500 {
+			return nil, false
+		}
+		return result, status == 0 || result.StatusCode == status
+	}
+
+	result, ok := fails(testCase)
+	if !ok {
+		return testCase, nil, fmt.Errorf("failed to reproduce a server error for %s", testCase.Name)
+	}
+	status = result.StatusCode
+
+	for progress := true; progress; {
+		progress = false
+		for _, candidate := range shrinkCandidates(testCase) {
+			if attempts >= maxShrinkAttempts || ctx.Err() != nil {
+				return testCase, result, nil
+			}
+			if candidateResult, ok := fails(candidate); ok {
+				testCase, result, progress = candidate, candidateResult, true
+				break
+			}
+		}
+	}
+	return testCase, result, nil
+}
+
+// shrinkCandidates lists smaller versions of a test case, removals first
+// since they shrink it the most
+func shrinkCandidates(testCase model.TestCase) []model.TestCase {
+	var removals, simplifications []model.TestCase
+	with := func(mutate func(*model.TestCase)) model.TestCase {
+		candidate := testCase
+		candidate.Params = append([]model.Param(nil), testCase.Params...)
+		mutate(&candidate)
+		return candidate
+	}
+
+	for i, param := range testCase.Params {
+		i := i
+		if param.In != "path" {
+			removals = append(removals, with(func(tc *model.TestCase) {
+				tc.Params = append(tc.Params[:i:i], tc.Params[i+1:]...)
+			}))
+		}
+		for _, simpler := range simplify(param.Value) {
+			simpler := simpler
+			simplifications = append(simplifications, with(func(tc *model.TestCase) {
+				tc.Params[i].Value = simpler
+			}))
+		}
+	}
+
+	if testCase.ContentType != "" {
+		for _, pointer := range bodyTargets(testCase.RequestBody, "") {
+			if pointer != "" {
+				body := removeAt(testCase.RequestBody, pointer)
+				removals = append(removals, with(func(tc *model.TestCase) {
+					tc.RequestBody = body
+				}))
+			}
+			for _, simpler := range simplify(valueAt(testCase.RequestBody, pointer)) {
+				simpler := simpler
+				body := replaceAt(testCase.RequestBody, pointer, func(interface{}) interface{} {
+					return simpler
+				})
+				simplifications = append(simplifications, with(func(tc *model.TestCase) {
+					tc.RequestBody = body
+				}))
+			}
+		}
+	}
+	return append(removals, simplifications...)
+}
+
+// simplify lists simpler replacements for a value: an empty container or
+// the value nested inside a chain of single-member containers, a shorter
+// string, or a smaller, whole number
+func simplify(v interface{}) []interface{} {
+	var simpler []interface{}
+	switch value := v.(type) {
+	case map[string]interface{}, []interface{}:
+		size := containerSize(value)
+		if size > 1 {
+			if _, ok := value.([]interface{}); ok {
+				simpler = append(simpler, []interface{}{})
+			} else {
+				simpler = append(simpler, map[string]interface{}{})
+			}
+		}
+		if size == 1 {
+			simpler = append(simpler, unwrapped(value)...)
+		}
+	case string:
+		runes := []rune(value)
+		if len(runes) > 0 {
+			simpler = append(simpler, "")
+		}
+		if len(runes) > 1 {
+			simpler = append(simpler, string(runes[:len(runes)/2]))
+		}
+	case float64, int64, int, json.Number:
+		n, err := strconv.ParseFloat(fmt.Sprint(value), 64)
+		if err != nil || n != 0 {
+			simpler = append(simpler, float64(0))
+		}
+		if err == nil && math.Trunc(n) != n && math.Trunc(n) != 0 {
+			simpler = append(simpler, math.Trunc(n))
+		}
+	}
+	return simpler
+}
+
+func containerSize(v interface{}) int {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		return len(value)
+	case []interface{}:
+		return len(value)
+	}
+	return 0
+}
+
+// unwrapped follows a chain of containers with a single member each,
+// returning the innermost value, the value halfway down and the only member
+func unwrapped(v interface{}) []interface{} {
+	var chain []interface{}
+	for containerSize(v) == 1 {
+		switch value := v.(type) {
+		case map[string]interface{}:
+			for _, member := range value {
+				v = member
+			}
+		case []interface{}:
+			v = value[0]
+		}
+		chain = append(chain, v)
+	}
+
+	var values []interface{}
+	used := make(map[int]bool)
+	for _, i := range []int{len(chain) - 1, len(chain) / 2, 0} {
+		if !used[i] {
+			used[i] = true
+			values = append(values, chain[i])
+		}
+	}
+	return values
+}
+
+// valueAt returns the value at a JSON pointer, or nil if there is none
+func valueAt(root interface{}, pointer string) interface{} {
+	for pointer != "" {
+		var token string
+		token, pointer, _ = strings.Cut(pointer[1:], "/")
+		if pointer != "" {
+			pointer = "/" + pointer
+		}
+		token = unescapeToken(token)
+
+		switch value := root.(type) {
+		case map[string]interface{}:
+			root = value[token]
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(value) {
+				return nil
+			}
+			root = value[i]
+		default:
+			return nil
+		}
+	}
+	return root
+}
+
+// removeAt returns a copy of root without the property or array item at
+// pointer
+func removeAt(root interface{}, pointer string) interface{} {
+	slash := strings.LastIndexByte(pointer, '/')
+	parent, token := pointer[:slash], unescapeToken(pointer[slash+1:])
+	return replaceAt(root, parent, func(v interface{}) interface{} {
+		switch value := v.(type) {
+		case map[string]interface{}:
+			out := make(map[string]interface{}, len(value))
+			for k, member := range value {
+				if k != token {
+					out[k] = member
+				}
+			}
+			return out
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(value) {
+				return v
+			}
+			return append(value[:i:i], value[i+1:]...)
+		}
+		return v
+	})
+}
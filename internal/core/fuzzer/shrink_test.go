@@ -0,0 +1,135 @@
+package fuzzer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/BarneyRubble12/specdrill/internal/core/domain"
+	"github.com/BarneyRubble12/specdrill/internal/core/executor"
+	"github.com/BarneyRubble12/specdrill/internal/core/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShrink(t *testing.T) {
+	// Fails whenever the body has a crash property nested at any depth
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if hasCrash(body) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	spec := &domain.APISpec{BaseURL: server.URL}
+	f := newFuzzer(executor.Options{})
+
+	tests := []struct {
+		name     string
+		testCase model.TestCase
+		wantErr  bool
+		want     model.TestCase
+		wantCurl string
+	}{
+		{
+			name: "Removes properties, parameters and nesting",
+			testCase: model.TestCase{
+				Name:        "POST /pets: fuzz #3",
+				Method:      "POST",
+				Path:        "/pets",
+				ContentType: "application/json",
+				Params:      []model.Param{{Name: "limit", In: "query", Value: 10.5}},
+				RequestBody: map[string]interface{}{
+					"name": "Rex",
+					"tags": []interface{}{"good", "boy"},
+					"meta": []interface{}{[]interface{}{map[string]interface{}{"crash": "a long string"}}},
+				},
+			},
+			want: model.TestCase{
+				Name:        "POST /pets: fuzz #3",
+				Method:      "POST",
+				Path:        "/pets",
+				ContentType: "application/json",
+				RequestBody: map[string]interface{}{"crash": ""},
+			},
+			wantCurl: "curl -X POST '" + server.URL + "/pets' -H 'Accept: application/json' -H 'Content-Type: application/json' --data-binary '{\"crash\":\"\"}'",
+		},
+		{
+			name: "Case that does not fail",
+			testCase: model.TestCase{
+				Name:        "POST /pets",
+				Method:      "POST",
+				Path:        "/pets",
+				ContentType: "application/json",
+				RequestBody: map[string]interface{}{"name": "Rex"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, result, err := f.Shrink(context.Background(), spec, tt.testCase)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, http.StatusInternalServerError, result.StatusCode)
+			assert.Equal(t, tt.wantCurl, result.Request.Curl())
+		})
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  []interface{}
+	}{
+		{"Long string", "abcd", []interface{}{"", "ab"}},
+		{"Empty string", "", nil},
+		{"Fraction", 12.5, []interface{}{float64(0), float64(12)}},
+		{"Zero", float64(0), nil},
+		{"Huge number", json.Number("1e400"), []interface{}{float64(0)}},
+		{"Nested single members", []interface{}{map[string]interface{}{"a": []interface{}{true}}}, []interface{}{
+			true,
+			[]interface{}{true},
+			map[string]interface{}{"a": []interface{}{true}},
+		}},
+		{"Object", map[string]interface{}{"a": 1.0, "b": 2.0}, []interface{}{map[string]interface{}{}}},
+		{"Boolean", true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, simplify(tt.value))
+		})
+	}
+}
+
+func hasCrash(v interface{}) bool {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if _, ok := value["crash"]; ok {
+			return true
+		}
+		for _, member := range value {
+			if hasCrash(member) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			if hasCrash(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/BarneyRubble12/specdrill/internal/core/domain"
+	"github.com/BarneyRubble12/specdrill/internal/core/model"
+)
+
+// BoundaryCases derives cases probing the edges of every numeric range,
+// string length and array size an operation's parameters and request body
+// declare: the boundary itself and the value just beyond it, plus empty
+// strings and arrays. Cases at a boundary expect success; cases beyond it
+// are negative and expect a 4xx.
+func (g *Generator) BoundaryCases(method, path string, pathItem domain.PathItem) []model.TestCase {
+	operation := pathItem.Operation(method)
+	if operation == nil {
+		return nil
+	}
+	base := g.CreateTestCase(method, path, pathItem)
+
+	var cases []model.TestCase
+	params := pathItem.ParametersFor(operation)
+	for i, param := range base.Params {
+		declared := findParameter(params, param.Name, param.In)
+		for _, boundary := range g.boundaryValues(declared.Schema, param.Value) {
+			testCase := deriveCase(base, fmt.Sprintf("%s parameter %s %s", param.In, param.Name, boundary.description), boundary.valid)
+			testCase.Params[i].Value = boundary.value
+			cases = append(cases, testCase)
+		}
+	}
+
+	if operation.RequestBody == nil || base.ContentType == "" {
+		return cases
+	}
+	schema := operation.RequestBody.Content[base.ContentType].Schema
+	leaf := func(s domain.Schema, value interface{}, pointer string) []variant {
+		return prefixed(pointer, g.boundaryValues(s, value))
+	}
+	for _, boundary := range bodyVariants(schema, base.RequestBody, "", 0, leaf) {
+		testCase := deriveCase(base, "body "+boundary.description, boundary.valid)
+		testCase.RequestBody = boundary.value
+		cases = append(cases, testCase)
+	}
+	return cases
+}
+
+// boundaryValues lists the values at and just beyond each bound a schema
+// declares. current is the generated value, whose type decides which
+// bounds apply. Lengths and item counts beyond the size limit are not
+// probed.
+func (g *Generator) boundaryValues(s domain.Schema, current interface{}) []variant {
+	s = s.MergeAllOf()
+	maxSize := g.maxSize()
+	var values []variant
+	add := func(description string, value interface{}, valid bool) {
+		for _, existing := range values {
+			// An empty value can coincide with the one just below a minimum
+			if fmt.Sprint(existing.value) == fmt.Sprint(value) && existing.valid == valid {
+				return
+			}
+		}
+		values = append(values, variant{description: description, value: value, valid: valid})
+	}
+
+	switch schemaType(s) {
+	case "integer", "number":
+		integer := schemaType(s) == "integer"
+		lo, hi := numericBounds(s, integer)
+		if s.Minimum != nil {
+			switch {
+			case integer && *s.Minimum != math.Trunc(*s.Minimum):
+				// No integer lies on a fractional bound, so probe the ones
+				// either side of it
+				bound := exclusiveName("minimum", s.ExclusiveMinimum)
+				add(fmt.Sprintf("just above %s %v", bound, *s.Minimum), numeric(lo, integer, lo), true)
+				add(fmt.Sprintf("below %s %v", bound, *s.Minimum), numeric(lo-1, integer, lo-1), false)
+			case s.ExclusiveMinimum:
+				add(fmt.Sprintf("at exclusive minimum %v", *s.Minimum), numeric(*s.Minimum, integer, *s.Minimum), false)
+				add(fmt.Sprintf("just above exclusive minimum %v", *s.Minimum), numeric(lo, integer, lo), true)
+			default:
+				add(fmt.Sprintf("at minimum %v", *s.Minimum), numeric(lo, integer, lo), true)
+				add(fmt.Sprintf("below minimum %v", *s.Minimum), numeric(lo-1, integer, lo-1), false)
+			}
+		}
+		if s.Maximum != nil {
+			switch {
+			case integer && *s.Maximum != math.Trunc(*s.Maximum):
+				bound := exclusiveName("maximum", s.ExclusiveMaximum)
+				add(fmt.Sprintf("just below %s %v", bound, *s.Maximum), numeric(hi, integer, hi), true)
+				add(fmt.Sprintf("above %s %v", bound, *s.Maximum), numeric(hi+1, integer, hi+1), false)
+			case s.ExclusiveMaximum:
+				add(fmt.Sprintf("at exclusive maximum %v", *s.Maximum), numeric(*s.Maximum, integer, *s.Maximum), false)
+				add(fmt.Sprintf("just below exclusive maximum %v", *s.Maximum), numeric(hi, integer, hi), true)
+			default:
+				add(fmt.Sprintf("at maximum %v", *s.Maximum), numeric(hi, integer, hi), true)
+				add(fmt.Sprintf("above maximum %v", *s.Maximum), numeric(hi+1, integer, hi+1), false)
+			}
+		}
+	case "string":
+		if _, isString := current.(string); !isString || s.MinLength == nil && s.MaxLength == nil {
+			break
+		}
+		if s.MinLength != nil {
+			if *s.MinLength <= maxSize {
+				add(fmt.Sprintf("at minLength %d", *s.MinLength), lengthString(s, *s.MinLength), true)
+			}
+			if *s.MinLength > 0 && *s.MinLength <= maxSize+1 {
+				add(fmt.Sprintf("shorter than minLength %d", *s.MinLength), lengthString(s, *s.MinLength-1), false)
+			}
+		}
+		if s.MaxLength != nil {
+			if *s.MaxLength <= maxSize {
+				add(fmt.Sprintf("at maxLength %d", *s.MaxLength), lengthString(s, *s.MaxLength), true)
+			}
+			if *s.MaxLength < maxSize {
+				add(fmt.Sprintf("longer than maxLength %d", *s.MaxLength), lengthString(s, *s.MaxLength+1), false)
+			}
+		}
+		add("empty", "", s.MinLength == nil || *s.MinLength == 0)
+	case "array":
+		if _, isArray := current.([]interface{}); !isArray || s.MinItems == nil && s.MaxItems == nil {
+			break
+		}
+		if s.MinItems != nil {
+			if *s.MinItems <= maxSize {
+				add(fmt.Sprintf("at minItems %d", *s.MinItems), g.items(s, *s.MinItems), true)
+			}
+			if *s.MinItems > 0 && *s.MinItems <= maxSize+1 {
+				add(fmt.Sprintf("fewer than minItems %d", *s.MinItems), g.items(s, *s.MinItems-1), false)
+			}
+		}
+		if s.MaxItems != nil {
+			if *s.MaxItems <= maxSize {
+				add(fmt.Sprintf("at maxItems %d", *s.MaxItems), g.items(s, *s.MaxItems), true)
+			}
+			if *s.MaxItems < maxSize {
+				add(fmt.Sprintf("more than maxItems %d", *s.MaxItems), g.items(s, *s.MaxItems+1), false)
+			}
+		}
+		add("empty", []interface{}{}, s.MinItems == nil || *s.MinItems == 0)
+	}
+	return values
+}
+
+// exclusiveName names a bound, marking it when exclusive
+func exclusiveName(bound string, exclusive bool) string {
+	if exclusive {
+		return "exclusive " + bound
+	}
+	return bound
+}
+
+// lengthString builds a string of exactly n characters, matching the
+// schema's pattern where possible
+func lengthString(s domain.Schema, n int) string {
+	if s.Pattern != "" {
+		if v, ok := patternString(s.Pattern, n, n); ok {
+			return v
+		}
+	}
+	return strings.Repeat("a", n)
+}
+
+// items builds an array of exactly n items for an array schema, distinct
+// when the schema requires unique items
+func (g *Generator) items(s domain.Schema, n int) []interface{} {
+	var item domain.Schema
+	if s.Items != nil {
+		item = *s.Items
+	}
+	items := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		index := 0
+		if s.UniqueItems {
+			index = i
+		}
+		v, _ := g.value(item, 1, index)
+		items = append(items, v)
+	}
+	return items
+}
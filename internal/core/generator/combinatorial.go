@@ -0,0 +1,226 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/BarneyRubble12/specdrill/internal/core/domain"
+	"github.com/BarneyRubble12/specdrill/internal/core/model"
+)
+
+// factor is a parameter whose values are combined
+type factor struct {
+	param    domain.Parameter
+	values   []interface{}
+	position int // Declaration order, for naming cases
+}
+
+// tuple is a combination of values for a set of factors, as indexes into
+// each factor's values
+type tuple struct {
+	factors []int
+	values  []int
+	covered bool
+}
+
+// CombinationCases covers every combination of values of any Strength of an
+// operation's enum and boolean parameters (every pair by default) with as
+// few cases as it can find. Operations with fewer than two such parameters
+// yield none. Cases are capped at MaxCombinations, in which case the
+// coverage falls short of 100%.
+func (g *Generator) CombinationCases(method, path string, pathItem domain.PathItem) ([]model.TestCase, model.Coverage) {
+	operation := pathItem.Operation(method)
+	if operation == nil {
+		return nil, model.Coverage{}
+	}
+	var factors []factor
+	for _, param := range pathItem.ParametersFor(operation) {
+		if values := factorValues(param.Schema); len(values) > 1 {
+			factors = append(factors, factor{param: param, values: values, position: len(factors)})
+		}
+	}
+	if len(factors) < 2 {
+		return nil, model.Coverage{}
+	}
+	// Covering the largest factors first keeps the greedy search close to
+	// the fewest cases
+	sort.SliceStable(factors, func(i, j int) bool {
+		return len(factors[i].values) > len(factors[j].values)
+	})
+
+	strength := g.options.Strength
+	if strength < 2 {
+		strength = 2
+	}
+	if strength > len(factors) {
+		strength = len(factors)
+	}
+	coverage := model.Coverage{Method: method, Path: path, Strength: strength, Exhaustive: 1}
+	for _, f := range factors {
+		if coverage.Exhaustive > math.MaxInt/len(f.values) {
+			coverage.Exhaustive = math.MaxInt
+		} else {
+			coverage.Exhaustive *= len(f.values)
+		}
+	}
+
+	tuples := allTuples(factors, strength)
+	coverage.Total = len(tuples)
+	base := g.CreateTestCase(method, path, pathItem)
+
+	var cases []model.TestCase
+	for coverage.Covered < coverage.Total {
+		if g.options.MaxCombinations > 0 && len(cases) >= g.options.MaxCombinations {
+			break
+		}
+		row := nextRow(factors, tuples)
+		for i := range tuples {
+			if !tuples[i].covered && tuples[i].matches(row) {
+				tuples[i].covered = true
+				coverage.Covered++
+			}
+		}
+		cases = append(cases, combinationCase(base, factors, row))
+	}
+	coverage.Cases = len(cases)
+	return cases, coverage
+}
+
+// factorValues lists the values to combine for a parameter: its enum, or
+// both booleans
+func factorValues(s domain.Schema) []interface{} {
+	s = s.MergeAllOf()
+	switch {
+	case len(s.Enum) > 0:
+		return s.Enum
+	case s.Type == "boolean":
+		return []interface{}{true, false}
+	}
+	return nil
+}
+
+// allTuples lists every combination of values for every set of strength
+// factors
+func allTuples(factors []factor, strength int) []tuple {
+	var tuples []tuple
+	var choose func(start int, chosen []int)
+	choose = func(start int, chosen []int) {
+		if len(chosen) == strength {
+			values := make([]int, strength)
+			for {
+				tuples = append(tuples, tuple{
+					factors: append([]int(nil), chosen...),
+					values:  append([]int(nil), values...),
+				})
+				// Advance like an odometer over the factors' values
+				i := strength - 1
+				for ; i >= 0; i-- {
+					values[i]++
+					if values[i] < len(factors[chosen[i]].values) {
+						break
+					}
+					values[i] = 0
+				}
+				if i < 0 {
+					return
+				}
+			}
+		}
+		for f := start; f < len(factors); f++ {
+			choose(f+1, append(chosen, f))
+		}
+	}
+	choose(0, nil)
+	return tuples
+}
+
+// nextRow builds a case greedily: it starts from the first uncovered tuple,
+// then gives each remaining factor the value completing the most uncovered
+// tuples
+func nextRow(factors []factor, tuples []tuple) []int {
+	row := make([]int, len(factors))
+	for i := range row {
+		row[i] = -1
+	}
+	for _, t := range tuples {
+		if !t.covered {
+			for i, f := range t.factors {
+				row[f] = t.values[i]
+			}
+			break
+		}
+	}
+
+	for f := range factors {
+		if row[f] >= 0 {
+			continue
+		}
+		best, bestCount := 0, -1
+		for v := range factors[f].values {
+			row[f] = v
+			count := 0
+			for _, t := range tuples {
+				if !t.covered && t.involves(f) && t.matches(row) {
+					count++
+				}
+			}
+			if count > bestCount {
+				best, bestCount = v, count
+			}
+		}
+		row[f] = best
+	}
+	return row
+}
+
+func (t tuple) involves(f int) bool {
+	for _, candidate := range t.factors {
+		if candidate == f {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether a row assigns every value of the tuple
+func (t tuple) matches(row []int) bool {
+	for i, f := range t.factors {
+		if row[f] != t.values[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// combinationCase sets the parameters of a happy-path case to one row of
+// values, naming it after the combination
+func combinationCase(base model.TestCase, factors []factor, row []int) model.TestCase {
+	parts := make([]string, len(factors))
+	for i, f := range factors {
+		parts[f.position] = fmt.Sprintf("%s=%v", f.param.Name, f.values[row[i]])
+	}
+	testCase := deriveCase(base, strings.Join(parts, ", "), true)
+
+	for i, f := range factors {
+		value := f.values[row[i]]
+		found := false
+		for j := range testCase.Params {
+			if testCase.Params[j].Name == f.param.Name && testCase.Params[j].In == f.param.In {
+				testCase.Params[j].Value = value
+				found = true
+			}
+		}
+		if !found {
+			testCase.Params = append(testCase.Params, model.Param{
+				Name:    f.param.Name,
+				In:      f.param.In,
+				Style:   f.param.SerializationStyle(),
+				Explode: f.param.Exploded(),
+				Value:   value,
+			})
+		}
+	}
+	return testCase
+}
@@ -0,0 +1,210 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/BarneyRubble12/specdrill/internal/core/domain"
+	"github.com/BarneyRubble12/specdrill/internal/core/model"
+)
+
+// Options controls which parts of an operation the generator fills in and
+// which cases a suite includes
+type Options struct {
+	// IncludeOptional sends optional parameters as well as required ones
+	IncludeOptional bool
+	// Negative adds cases that break one request rule each
+	Negative bool
+	// Boundary adds cases at and just beyond each declared bound
+	Boundary bool
+	// Combinations adds cases covering combinations of enum and boolean
+	// parameter values
+	Combinations bool
+	// Strength is how many parameters combination cases combine; values
+	// below 2 mean pairwise
+	Strength int
+	// MaxCombinations caps the combination cases per operation; zero means
+	// no cap
+	MaxCombinations int
+	// MaxSize is the longest string and largest array negative and boundary
+	// cases build; cases that need more are not generated. Zero means
+	// DefaultMaxSize.
+	MaxSize int
+}
+
+// DefaultMaxSize is the MaxSize used when none is set. Specs often declare
+// bounds such as maxLength 2147483647 that cannot be probed in memory.
+const DefaultMaxSize = 64 * 1024
+
+// Generator builds test cases and request data from an API spec
+type Generator struct {
+	options Options
+}
+
+// NewGenerator creates a new Generator instance
+func NewGenerator(options Options) *Generator {
+	return &Generator{
+		options: options,
+	}
+}
+
+// maxSize returns the longest string or largest array a case may hold
+func (g *Generator) maxSize() int {
+	if g.options.MaxSize > 0 {
+		return g.options.MaxSize
+	}
+	return DefaultMaxSize
+}
+
+// CreateTestCase creates a test case for the operation a path item defines
+// for method
+func (g *Generator) CreateTestCase(method, path string, pathItem domain.PathItem) model.TestCase {
+	operation := pathItem.Operation(method)
+	testCase := model.TestCase{
+		Name:             fmt.Sprintf("%s %s", method, path),
+		Method:           method,
+		Path:             path,
+		ExpectedStatuses: []string{"2XX"},
+	}
+	if operation == nil {
+		return testCase
+	}
+	testCase.DocumentedStatuses = documentedStatuses(operation)
+	testCase.ExpectedStatuses = successStatuses(testCase.DocumentedStatuses)
+	testCase.Description = operation.Summary
+
+	// Fill in every templated path segment, plus the query, header and
+	// cookie parameters selected by the options
+	for _, param := range pathItem.ParametersFor(operation) {
+		if param.In != "path" && !param.Required && !g.options.IncludeOptional {
+			continue
+		}
+		testCase.Params = append(testCase.Params, model.Param{
+			Name:    param.Name,
+			In:      param.In,
+			Style:   param.SerializationStyle(),
+			Explode: param.Exploded(),
+			Value:   g.ParameterValue(param),
+		})
+	}
+
+	// Add request body if the operation declares one
+	if contentType, body, ok := g.RequestBody(operation); ok {
+		testCase.ContentType = contentType
+		testCase.RequestBody = body
+	}
+
+	return testCase
+}
+
+// RequestBody picks the media type to send for an operation and generates
+// a body for it. It reports false when the operation takes no body.
+func (g *Generator) RequestBody(operation *domain.Operation) (string, interface{}, bool) {
+	if operation.RequestBody == nil || len(operation.RequestBody.Content) == 0 {
+		return "", nil, false
+	}
+
+	contentType := preferredMediaType(operation.RequestBody.Content)
+	mediaType := operation.RequestBody.Content[contentType]
+
+	if mediaType.Example != nil {
+		return contentType, mediaType.Example, true
+	}
+	if example, ok := firstExample(mediaType.Examples); ok {
+		return contentType, example, true
+	}
+	return contentType, g.Value(mediaType.Schema), true
+}
+
+// preferredMediaType chooses which of the declared media types to send,
+// favouring JSON, then form encodings, then plain text
+func preferredMediaType(content map[string]domain.MediaType) string {
+	types := make([]string, 0, len(content))
+	for contentType := range content {
+		types = append(types, contentType)
+	}
+	sort.Strings(types)
+
+	rank := func(contentType string) int {
+		mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+		switch {
+		case mediaType == "application/json":
+			return 0
+		case strings.HasSuffix(mediaType, "+json"):
+			return 1
+		case mediaType == "application/x-www-form-urlencoded":
+			return 2
+		case mediaType == "multipart/form-data":
+			return 3
+		case strings.HasPrefix(mediaType, "text/"):
+			return 4
+		}
+		return 5
+	}
+	sort.SliceStable(types, func(i, j int) bool {
+		return rank(types[i]) < rank(types[j])
+	})
+	return types[0]
+}
+
+// firstExample returns the value of the alphabetically first named example
+func firstExample(examples map[string]domain.Example) (interface{}, bool) {
+	names := make([]string, 0, len(examples))
+	for name := range examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if value := examples[name].Value; value != nil {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// CompleteTestCase fills in what a hand-written or saved test case may leave
+// out: the statuses its operation documents, the success statuses when it
+// expects none, and a JSON content type for a body without one
+func (g *Generator) CompleteTestCase(pathItem domain.PathItem, testCase model.TestCase) model.TestCase {
+	if operation := pathItem.Operation(testCase.Method); operation != nil && len(testCase.DocumentedStatuses) == 0 {
+		testCase.DocumentedStatuses = documentedStatuses(operation)
+	}
+	if len(testCase.ExpectedStatuses) == 0 {
+		testCase.ExpectedStatuses = successStatuses(testCase.DocumentedStatuses)
+	}
+	if testCase.ContentType == "" && testCase.RequestBody != nil {
+		testCase.ContentType = "application/json"
+	}
+	return testCase
+}
+
+// documentedStatuses returns the response keys an operation documents
+func documentedStatuses(operation *domain.Operation) []string {
+	statuses := make([]string, 0, len(operation.Responses))
+	for status := range operation.Responses {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	return statuses
+}
+
+// successStatuses picks the responses a valid request should produce: the
+// documented 2xx codes and ranges, else default, else any 2xx code
+func successStatuses(documented []string) []string {
+	var statuses []string
+	for _, status := range documented {
+		if strings.HasPrefix(status, "2") {
+			statuses = append(statuses, status)
+		}
+	}
+	if len(statuses) > 0 {
+		return statuses
+	}
+	for _, status := range documented {
+		if status == "default" {
+			return []string{status}
+		}
+	}
+	return []string{"2XX"}
+}
@@ -0,0 +1,679 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/BarneyRubble12/specdrill/internal/core/domain"
+	"github.com/BarneyRubble12/specdrill/internal/core/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   domain.Schema
+		want     interface{}
+		validate func(*testing.T, interface{})
+	}{
+		{
+			name:   "Const wins over everything",
+			schema: domain.Schema{Type: "string", HasConst: true, Const: "fixed", Example: "example"},
+			want:   "fixed",
+		},
+		{
+			name:   "Example preferred",
+			schema: domain.Schema{Type: "string", Example: "example", Default: "default", Enum: []interface{}{"a"}},
+			want:   "example",
+		},
+		{
+			name:   "Examples array",
+			schema: domain.Schema{Type: "string", Examples: []interface{}{"first", "second"}},
+			want:   "first",
+		},
+		{
+			name:   "Default before enum",
+			schema: domain.Schema{Type: "string", Default: "default", Enum: []interface{}{"a"}},
+			want:   "default",
+		},
+		{
+			name:   "Enum",
+			schema: domain.Schema{Type: "string", Enum: []interface{}{"a", "b"}},
+			want:   "a",
+		},
+		{
+			name:   "Format",
+			schema: domain.Schema{Type: "string", Format: "uuid"},
+			want:   "123e4567-e89b-12d3-a456-426614174000",
+		},
+		{
+			name:   "String length",
+			schema: domain.Schema{Type: "string", MinLength: intPtr(10)},
+			want:   "stringaaaa",
+		},
+		{
+			name:   "String max length",
+			schema: domain.Schema{Type: "string", MaxLength: intPtr(3)},
+			want:   "str",
+		},
+		{
+			name:   "Pattern",
+			schema: domain.Schema{Type: "string", Pattern: `^[A-Z]{2}-\d{3,}$`, MinLength: intPtr(7)},
+			validate: func(t *testing.T, v interface{}) {
+				assert.Regexp(t, regexp.MustCompile(`^[A-Z]{2}-\d{3,}$`), v)
+				assert.GreaterOrEqual(t, len(v.(string)), 7)
+			},
+		},
+		{
+			name:   "Integer exclusive minimum",
+			schema: domain.Schema{Type: "integer", Minimum: floatPtr(10), ExclusiveMinimum: true},
+			want:   int64(11),
+		},
+		{
+			name:   "Integer maximum",
+			schema: domain.Schema{Type: "integer", Maximum: floatPtr(-5)},
+			want:   int64(-5),
+		},
+		{
+			name:   "Number multipleOf",
+			schema: domain.Schema{Type: "number", Minimum: floatPtr(1.1), MultipleOf: floatPtr(0.5)},
+			want:   1.5,
+		},
+		{
+			name:   "Boolean",
+			schema: domain.Schema{Type: "boolean"},
+			want:   true,
+		},
+		{
+			name:   "Unique array items",
+			schema: domain.Schema{Type: "array", MinItems: intPtr(3), UniqueItems: true, Items: &domain.Schema{Type: "integer"}},
+			want:   []interface{}{int64(1), int64(2), int64(3)},
+		},
+		{
+			name: "Prefix items",
+			schema: domain.Schema{
+				Type:        "array",
+				PrefixItems: []domain.Schema{{Type: "number"}, {Type: "string"}},
+				Items:       &domain.Schema{False: true},
+			},
+			want: []interface{}{1.0, "string"},
+		},
+		{
+			name: "Object skips read-only and recursive properties",
+			schema: domain.Schema{
+				Type: "object",
+				Properties: map[string]domain.Schema{
+					"id":       {Type: "integer", ReadOnly: true},
+					"name":     {Type: "string"},
+					"parent":   {Ref: "#/components/schemas/Node"},
+					"nickname": {Type: "string", Nullable: true, Example: "Rex"},
+				},
+			},
+			want: map[string]interface{}{"name": "string", "nickname": "Rex"},
+		},
+		{
+			name: "AllOf merged",
+			schema: domain.Schema{AllOf: []domain.Schema{
+				{Type: "object", Properties: map[string]domain.Schema{"a": {Type: "integer"}}},
+				{Properties: map[string]domain.Schema{"b": {Type: "boolean"}}},
+			}},
+			want: map[string]interface{}{"a": int64(1), "b": true},
+		},
+		{
+			name: "OneOf with discriminator",
+			schema: domain.Schema{
+				OneOf: []domain.Schema{
+					{Ref: "#/components/schemas/Cat", Type: "object", Properties: map[string]domain.Schema{
+						"petType": {Type: "string"},
+						"indoor":  {Type: "boolean"},
+					}},
+					{Ref: "#/components/schemas/Dog", Type: "object"},
+				},
+				Discriminator: &domain.Discriminator{PropertyName: "petType"},
+			},
+			want: map[string]interface{}{"petType": "Cat", "indoor": true},
+		},
+		{
+			name:   "Not excludes enum values",
+			schema: domain.Schema{Type: "string", Enum: []interface{}{"a", "b"}, Not: &domain.Schema{Enum: []interface{}{"a"}}},
+			want:   "b",
+		},
+		{
+			name:   "Untyped schema with properties",
+			schema: domain.Schema{Properties: map[string]domain.Schema{"x": {Type: "string", Format: "date"}}},
+			want:   map[string]interface{}{"x": "2024-01-01"},
+		},
+	}
+
+	g := NewGenerator(Options{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := g.Value(tt.schema)
+			if tt.validate != nil {
+				tt.validate(t, v)
+				return
+			}
+			assert.Equal(t, tt.want, v)
+		})
+	}
+}
+
+func TestRequestBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		operation   *domain.Operation
+		wantOK      bool
+		contentType string
+		body        interface{}
+	}{
+		{
+			name:      "No request body",
+			operation: &domain.Operation{},
+		},
+		{
+			name: "JSON preferred over XML and forms",
+			operation: &domain.Operation{RequestBody: &domain.RequestBody{Content: map[string]domain.MediaType{
+				"application/xml":                   {Schema: domain.Schema{Type: "string"}},
+				"application/x-www-form-urlencoded": {Schema: domain.Schema{Type: "string"}},
+				"application/json":                  {Schema: domain.Schema{Type: "integer"}},
+			}}},
+			wantOK:      true,
+			contentType: "application/json",
+			body:        int64(1),
+		},
+		{
+			name: "Media type example",
+			operation: &domain.Operation{RequestBody: &domain.RequestBody{Content: map[string]domain.MediaType{
+				"application/vnd.api+json": {Example: map[string]interface{}{"name": "Rex"}},
+			}}},
+			wantOK:      true,
+			contentType: "application/vnd.api+json",
+			body:        map[string]interface{}{"name": "Rex"},
+		},
+		{
+			name: "Named examples",
+			operation: &domain.Operation{RequestBody: &domain.RequestBody{Content: map[string]domain.MediaType{
+				"application/json": {Examples: map[string]domain.Example{
+					"second": {Value: "b"},
+					"first":  {Value: "a"},
+				}},
+			}}},
+			wantOK:      true,
+			contentType: "application/json",
+			body:        "a",
+		},
+	}
+
+	g := NewGenerator(Options{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contentType, body, ok := g.RequestBody(tt.operation)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.contentType, contentType)
+			assert.Equal(t, tt.body, body)
+		})
+	}
+}
+
+func TestCreateTestCaseParams(t *testing.T) {
+	explode := false
+	pathItem := domain.PathItem{
+		Parameters: []domain.Parameter{
+			{Name: "ownerId", In: "path", Required: true, Schema: domain.Schema{Type: "string", Pattern: "^[A-Z]{3}$"}},
+			{Name: "petId", In: "path", Required: true, Schema: domain.Schema{Type: "integer"}},
+			{Name: "verbose", In: "query", Schema: domain.Schema{Type: "boolean"}},
+		},
+		Get: &domain.Operation{
+			Parameters: []domain.Parameter{
+				{Name: "petId", In: "path", Required: true, Examples: map[string]domain.Example{"cat": {Value: "felix"}}},
+				{Name: "limit", In: "query", Required: true, Schema: domain.Schema{Type: "integer", Minimum: floatPtr(10)}},
+				{Name: "tags", In: "query", Style: "pipeDelimited", Explode: &explode, Schema: domain.Schema{Type: "array", Items: &domain.Schema{Type: "string"}}},
+				{Name: "X-Request-Id", In: "header", Required: true, Schema: domain.Schema{Type: "string", Format: "uuid"}},
+				{Name: "session", In: "cookie", Schema: domain.Schema{Type: "string"}},
+			},
+		},
+	}
+	required := []model.Param{
+		{Name: "ownerId", In: "path", Style: "simple", Value: "AAA"},
+		{Name: "petId", In: "path", Style: "simple", Value: "felix"},
+		{Name: "limit", In: "query", Style: "form", Explode: true, Value: int64(10)},
+		{Name: "X-Request-Id", In: "header", Style: "simple", Value: "123e4567-e89b-12d3-a456-426614174000"},
+	}
+
+	tests := []struct {
+		name    string
+		options Options
+		want    []model.Param
+	}{
+		{
+			name: "Required parameters only",
+			want: required,
+		},
+		{
+			name:    "Optional parameters included",
+			options: Options{IncludeOptional: true},
+			want: []model.Param{
+				{Name: "ownerId", In: "path", Style: "simple", Value: "AAA"},
+				{Name: "verbose", In: "query", Style: "form", Explode: true, Value: true},
+				required[1],
+				required[2],
+				{Name: "tags", In: "query", Style: "pipeDelimited", Value: []interface{}{"string"}},
+				required[3],
+				{Name: "session", In: "cookie", Style: "form", Explode: true, Value: "string"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testCase := NewGenerator(tt.options).CreateTestCase("GET", "/owners/{ownerId}/pets/{petId}", pathItem)
+			assert.Equal(t, tt.want, testCase.Params)
+		})
+	}
+}
+
+func TestCreateTestCaseExpectedStatuses(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses map[string]domain.Response
+		want      []string
+	}{
+		{
+			name:      "Exact success codes",
+			responses: map[string]domain.Response{"201": {}, "202": {}, "404": {}, "default": {}},
+			want:      []string{"201", "202"},
+		},
+		{
+			name:      "Success range",
+			responses: map[string]domain.Response{"2XX": {}, "4XX": {}},
+			want:      []string{"2XX"},
+		},
+		{
+			name:      "Only default",
+			responses: map[string]domain.Response{"400": {}, "default": {}},
+			want:      []string{"default"},
+		},
+		{
+			name: "No responses",
+			want: []string{"2XX"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pathItem := domain.PathItem{Post: &domain.Operation{Responses: tt.responses}}
+			testCase := NewGenerator(Options{}).CreateTestCase("POST", "/pets", pathItem)
+			assert.Equal(t, tt.want, testCase.ExpectedStatuses)
+		})
+	}
+}
+
+func TestNegativeCases(t *testing.T) {
+	pathItem := domain.PathItem{
+		Post: &domain.Operation{
+			Parameters: []domain.Parameter{
+				{Name: "limit", In: "query", Required: true, Schema: domain.Schema{Type: "integer", Minimum: floatPtr(1), Maximum: floatPtr(50)}},
+				{Name: "X-Trace", In: "header", Schema: domain.Schema{Type: "string"}},
+			},
+			RequestBody: &domain.RequestBody{
+				Required: true,
+				Content: map[string]domain.MediaType{
+					"application/json": {Schema: domain.Schema{
+						Type:     "object",
+						Required: []string{"name"},
+						Properties: map[string]domain.Schema{
+							"name": {Type: "string", MinLength: intPtr(2), Pattern: "^[a-z]+$"},
+							"kind": {Type: "string", Enum: []interface{}{"cat", "dog"}},
+						},
+					}},
+				},
+			},
+			Responses: map[string]domain.Response{"201": {}, "400": {}},
+		},
+	}
+
+	cases := NewGenerator(Options{}).NegativeCases("POST", "/pets", pathItem)
+
+	descriptions := make([]string, len(cases))
+	for i, testCase := range cases {
+		descriptions[i] = testCase.Description
+		assert.True(t, testCase.Negative)
+		assert.Equal(t, []string{"400", "4XX"}, testCase.ExpectedStatuses)
+		assert.Equal(t, "POST /pets: "+testCase.Description, testCase.Name)
+	}
+	assert.Equal(t, []string{
+		"missing required query parameter limit",
+		"query parameter limit of the wrong type (want integer)",
+		"query parameter limit not an integer",
+		"query parameter limit below minimum 1",
+		"query parameter limit above maximum 50",
+		"missing required request body",
+		"body missing required property /name",
+		"body /kind of the wrong type (want string)",
+		"body /kind outside its enum",
+		"body /name of the wrong type (want string)",
+		"body /name shorter than minLength 2",
+		"body /name not matching pattern ^[a-z]+$",
+	}, descriptions)
+
+	byDescription := make(map[string]model.TestCase)
+	for _, testCase := range cases {
+		byDescription[testCase.Description] = testCase
+	}
+	assert.Empty(t, byDescription["missing required query parameter limit"].Params)
+	assert.Equal(t, int64(0), byDescription["query parameter limit below minimum 1"].Params[0].Value)
+	assert.Equal(t, map[string]interface{}{"kind": "cat"}, byDescription["body missing required property /name"].RequestBody)
+	assert.Equal(t, map[string]interface{}{"name": "aa", "kind": "not-in-enum"}, byDescription["body /kind outside its enum"].RequestBody)
+	assert.Empty(t, byDescription["missing required request body"].ContentType)
+}
+
+func TestNegativeCasesMaxSize(t *testing.T) {
+	pathItem := domain.PathItem{
+		Get: &domain.Operation{
+			Parameters: []domain.Parameter{
+				{Name: "q", In: "query", Required: true, Schema: domain.Schema{Type: "string", MaxLength: intPtr(math.MaxInt)}},
+				{Name: "code", In: "query", Required: true, Schema: domain.Schema{Type: "string", MinLength: intPtr(100)}},
+				{Name: "tag", In: "query", Required: true, Schema: domain.Schema{Type: "string", MaxLength: intPtr(8)}},
+			},
+			Responses: map[string]domain.Response{"200": {}},
+		},
+	}
+
+	cases := NewGenerator(Options{MaxSize: 16}).NegativeCases("GET", "/pets", pathItem)
+
+	var descriptions []string
+	for _, testCase := range cases {
+		descriptions = append(descriptions, testCase.Description)
+	}
+	assert.Contains(t, descriptions, "query parameter tag longer than maxLength 8")
+	for _, description := range descriptions {
+		assert.NotContains(t, description, "maxLength 9223372036854775807")
+		assert.NotContains(t, description, "shorter than minLength")
+	}
+}
+
+func TestBoundaryCases(t *testing.T) {
+	pathItem := domain.PathItem{
+		Put: &domain.Operation{
+			Parameters: []domain.Parameter{
+				{Name: "page", In: "query", Required: true, Schema: domain.Schema{Type: "integer", Minimum: floatPtr(1), Maximum: floatPtr(100)}},
+			},
+			RequestBody: &domain.RequestBody{Content: map[string]domain.MediaType{
+				"application/json": {Schema: domain.Schema{
+					Type: "object",
+					Properties: map[string]domain.Schema{
+						"code":   {Type: "string", MinLength: intPtr(2), MaxLength: intPtr(4), Pattern: "^[A-Z]+$"},
+						"ratio":  {Type: "number", Minimum: floatPtr(0), ExclusiveMinimum: true},
+						"tags":   {Type: "array", MaxItems: intPtr(2), UniqueItems: true, Items: &domain.Schema{Type: "string"}},
+						"plain":  {Type: "string"},
+						"nested": {Type: "object", Properties: map[string]domain.Schema{"n": {Type: "integer", Maximum: floatPtr(5), ExclusiveMaximum: true}}},
+					},
+				}},
+			}},
+			Responses: map[string]domain.Response{"200": {}, "422": {}},
+		},
+	}
+
+	cases := NewGenerator(Options{}).BoundaryCases("PUT", "/items", pathItem)
+
+	type probe struct {
+		description string
+		value       interface{}
+		negative    bool
+	}
+	var got []probe
+	for _, testCase := range cases {
+		var value interface{}
+		switch {
+		case strings.HasPrefix(testCase.Description, "query parameter page"):
+			value = testCase.Params[0].Value
+		default:
+			body := testCase.RequestBody.(map[string]interface{})
+			field := strings.Split(strings.TrimPrefix(testCase.Description, "body /"), " ")[0]
+			if strings.HasPrefix(field, "nested/") {
+				value = body["nested"].(map[string]interface{})["n"]
+			} else {
+				value = body[field]
+			}
+		}
+		got = append(got, probe{testCase.Description, value, testCase.Negative})
+
+		if testCase.Negative {
+			assert.Equal(t, []string{"422", "4XX"}, testCase.ExpectedStatuses, testCase.Description)
+		} else {
+			assert.Equal(t, []string{"200"}, testCase.ExpectedStatuses, testCase.Description)
+		}
+	}
+
+	assert.Equal(t, []probe{
+		{"query parameter page at minimum 1", int64(1), false},
+		{"query parameter page below minimum 1", int64(0), true},
+		{"query parameter page at maximum 100", int64(100), false},
+		{"query parameter page above maximum 100", int64(101), true},
+		{"body /code at minLength 2", "AA", false},
+		{"body /code shorter than minLength 2", "A", true},
+		{"body /code at maxLength 4", "AAAA", false},
+		{"body /code longer than maxLength 4", "AAAAA", true},
+		{"body /code empty", "", true},
+		{"body /nested/n at exclusive maximum 5", int64(5), true},
+		{"body /nested/n just below exclusive maximum 5", int64(4), false},
+		{"body /ratio at exclusive minimum 0", float64(0), true},
+		{"body /ratio just above exclusive minimum 0", math.Nextafter(0, 1), false},
+		{"body /tags at maxItems 2", []interface{}{"string", "string1"}, false},
+		{"body /tags more than maxItems 2", []interface{}{"string", "string1", "string2"}, true},
+		{"body /tags empty", []interface{}{}, false},
+	}, got)
+}
+
+func TestBoundaryCasesMaxSize(t *testing.T) {
+	pathItem := domain.PathItem{
+		Post: &domain.Operation{
+			RequestBody: &domain.RequestBody{Content: map[string]domain.MediaType{
+				"application/json": {Schema: domain.Schema{
+					Type: "object",
+					Properties: map[string]domain.Schema{
+						"name": {Type: "string", MaxLength: intPtr(math.MaxInt32)},
+						"ids":  {Type: "array", MaxItems: intPtr(math.MaxInt), Items: &domain.Schema{Type: "integer"}},
+						"code": {Type: "string", MaxLength: intPtr(DefaultMaxSize)},
+					},
+				}},
+			}},
+			Responses: map[string]domain.Response{"200": {}},
+		},
+	}
+
+	cases := NewGenerator(Options{}).BoundaryCases("POST", "/items", pathItem)
+
+	var descriptions []string
+	for _, testCase := range cases {
+		descriptions = append(descriptions, testCase.Description)
+	}
+	assert.Equal(t, []string{
+		"body /code at maxLength 65536",
+		"body /code empty",
+		"body /ids empty",
+		"body /name empty",
+	}, descriptions)
+}
+
+func TestBoundaryCasesFractionalIntegerBounds(t *testing.T) {
+	pathItem := domain.PathItem{
+		Get: &domain.Operation{
+			Parameters: []domain.Parameter{
+				{Name: "n", In: "query", Required: true, Schema: domain.Schema{
+					Type: "integer", Minimum: floatPtr(0.5), ExclusiveMinimum: true, Maximum: floatPtr(9.5),
+				}},
+			},
+			Responses: map[string]domain.Response{"200": {}},
+		},
+	}
+
+	cases := NewGenerator(Options{}).BoundaryCases("GET", "/items", pathItem)
+
+	got := make(map[string]interface{}, len(cases))
+	for _, testCase := range cases {
+		got[testCase.Description] = testCase.Params[0].Value
+	}
+	assert.Equal(t, map[string]interface{}{
+		"query parameter n just above exclusive minimum 0.5": int64(1),
+		"query parameter n below exclusive minimum 0.5":      int64(0),
+		"query parameter n just below maximum 9.5":           int64(9),
+		"query parameter n above maximum 9.5":                int64(10),
+	}, got)
+}
+
+func TestCombinationCases(t *testing.T) {
+	pathItem := domain.PathItem{
+		Get: &domain.Operation{
+			Parameters: []domain.Parameter{
+				{Name: "status", In: "query", Schema: domain.Schema{Type: "string", Enum: []interface{}{"available", "sold"}}},
+				{Name: "tame", In: "query", Required: true, Schema: domain.Schema{Type: "boolean"}},
+				{Name: "X-Vaccinated", In: "header", Schema: domain.Schema{Type: "boolean"}},
+				{Name: "size", In: "query", Schema: domain.Schema{Type: "string", Enum: []interface{}{"s", "m", "l"}}},
+				{Name: "name", In: "query", Required: true, Schema: domain.Schema{Type: "string"}},
+			},
+			Responses: map[string]domain.Response{"200": {}},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		options      Options
+		pathItem     domain.PathItem
+		wantCases    int
+		wantCoverage model.Coverage
+	}{
+		{
+			name:         "Pairwise",
+			pathItem:     pathItem,
+			wantCases:    6,
+			wantCoverage: model.Coverage{Method: "GET", Path: "/pets", Strength: 2, Covered: 30, Total: 30, Cases: 6, Exhaustive: 24},
+		},
+		{
+			name:         "Three-way",
+			options:      Options{Strength: 3},
+			pathItem:     pathItem,
+			wantCases:    12,
+			wantCoverage: model.Coverage{Method: "GET", Path: "/pets", Strength: 3, Covered: 44, Total: 44, Cases: 12, Exhaustive: 24},
+		},
+		{
+			name:         "Capped",
+			options:      Options{MaxCombinations: 2},
+			pathItem:     pathItem,
+			wantCases:    2,
+			wantCoverage: model.Coverage{Method: "GET", Path: "/pets", Strength: 2, Covered: 12, Total: 30, Cases: 2, Exhaustive: 24},
+		},
+		{
+			name: "Single factor",
+			pathItem: domain.PathItem{Get: &domain.Operation{Parameters: []domain.Parameter{
+				{Name: "tame", In: "query", Schema: domain.Schema{Type: "boolean"}},
+			}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cases, coverage := NewGenerator(tt.options).CombinationCases("GET", "/pets", tt.pathItem)
+			assert.Len(t, cases, tt.wantCases)
+			assert.Equal(t, tt.wantCoverage, coverage)
+
+			// Every case is named after its combination and sends it
+			for _, testCase := range cases {
+				assert.False(t, testCase.Negative)
+				assert.Equal(t, []string{"200"}, testCase.ExpectedStatuses)
+				sent := make([]string, 0, len(testCase.Params))
+				for _, param := range testCase.Params {
+					if param.Name != "name" {
+						sent = append(sent, fmt.Sprintf("%s=%v", param.Name, param.Value))
+					}
+				}
+				assert.ElementsMatch(t, strings.Split(testCase.Description, ", "), sent)
+				assert.Equal(t, "GET /pets: "+testCase.Description, testCase.Name)
+			}
+			if len(cases) > 0 {
+				assert.Equal(t, "GET /pets: status=available, tame=true, X-Vaccinated=true, size=s", cases[0].Name)
+			}
+		})
+	}
+}
+
+func TestGenerateSuite(t *testing.T) {
+	spec := &domain.APISpec{
+		Info:    domain.Info{Title: "Pets"},
+		BaseURL: "http://localhost:8080",
+		Paths: map[string]domain.PathItem{
+			"/pets": {
+				Get: &domain.Operation{
+					Parameters: []domain.Parameter{
+						{Name: "limit", In: "query", Required: true, Schema: domain.Schema{Type: "integer", Maximum: floatPtr(10)}},
+						{Name: "tame", In: "query", Schema: domain.Schema{Type: "boolean"}},
+						{Name: "sold", In: "query", Schema: domain.Schema{Type: "boolean"}},
+					},
+					Responses: map[string]domain.Response{"200": {}},
+				},
+				Post: &domain.Operation{Responses: map[string]domain.Response{"201": {}}},
+			},
+			"/owners": {
+				Get: &domain.Operation{Responses: map[string]domain.Response{"200": {}}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		options      Options
+		wantNames    []string
+		wantCoverage int
+	}{
+		{
+			name:      "Happy paths in path and method order",
+			wantNames: []string{"GET /owners", "GET /pets", "POST /pets"},
+		},
+		{
+			name:    "All kinds of cases",
+			options: Options{Negative: true, Boundary: true, Combinations: true},
+			wantNames: []string{
+				"GET /owners",
+				"GET /pets",
+				"GET /pets: missing required query parameter limit",
+				"GET /pets: query parameter limit of the wrong type (want integer)",
+				"GET /pets: query parameter limit not an integer",
+				"GET /pets: query parameter limit above maximum 10",
+				"GET /pets: query parameter limit at maximum 10",
+				"GET /pets: query parameter limit above maximum 10",
+				"GET /pets: tame=true, sold=true",
+				"GET /pets: tame=true, sold=false",
+				"GET /pets: tame=false, sold=true",
+				"GET /pets: tame=false, sold=false",
+				"POST /pets",
+			},
+			wantCoverage: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suite := NewGenerator(tt.options).GenerateSuite(spec)
+			assert.Equal(t, "Pets", suite.Name)
+			assert.Equal(t, "http://localhost:8080", suite.BaseURL)
+
+			var names []string
+			for _, testCase := range suite.TestCases {
+				names = append(names, testCase.Name)
+			}
+			assert.Equal(t, tt.wantNames, names)
+			assert.Len(t, suite.Coverage, tt.wantCoverage)
+		})
+	}
+}
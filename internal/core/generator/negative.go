@@ -0,0 +1,369 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BarneyRubble12/specdrill/internal/core/domain"
+	"github.com/BarneyRubble12/specdrill/internal/core/model"
+)
+
+// maxMutationDepth bounds how deep into nested request bodies negative
+// cases are generated
+const maxMutationDepth = 3
+
+// invalidFormats are values that break well-known string formats
+var invalidFormats = map[string]string{
+	"date-time": "not-a-date-time",
+	"date":      "not-a-date",
+	"email":     "not-an-email",
+	"uuid":      "not-a-uuid",
+	"uri":       "not a uri",
+	"url":       "not a url",
+	"ipv4":      "999.999.999.999",
+	"ipv6":      "not-an-ipv6",
+}
+
+// variant is a value to send in place of a generated one, and how it
+// relates to the schema
+type variant struct {
+	description string
+	value       interface{}
+	valid       bool
+}
+
+// NegativeCases derives cases from the happy-path case for an operation,
+// each breaking one rule of the request: a required body property or
+// parameter left out, or a value of the wrong type, out of range, violating
+// its pattern or length, or outside its enum. A correct API rejects every
+// one of them with a 4xx.
+func (g *Generator) NegativeCases(method, path string, pathItem domain.PathItem) []model.TestCase {
+	operation := pathItem.Operation(method)
+	if operation == nil {
+		return nil
+	}
+	base := g.CreateTestCase(method, path, pathItem)
+
+	var cases []model.TestCase
+	add := func(description string, mutate func(*model.TestCase)) {
+		testCase := deriveCase(base, description, false)
+		mutate(&testCase)
+		cases = append(cases, testCase)
+	}
+
+	// Parameters
+	params := pathItem.ParametersFor(operation)
+	for i, param := range base.Params {
+		declared := findParameter(params, param.Name, param.In)
+		i := i
+		if declared.Required && param.In != "path" {
+			add(fmt.Sprintf("missing required %s parameter %s", param.In, param.Name), func(tc *model.TestCase) {
+				tc.Params = append(tc.Params[:i:i], tc.Params[i+1:]...)
+			})
+		}
+		for _, invalid := range invalidValues(declared.Schema, true, g.maxSize()) {
+			invalid := invalid
+			add(fmt.Sprintf("%s parameter %s %s", param.In, param.Name, invalid.description), func(tc *model.TestCase) {
+				tc.Params[i].Value = invalid.value
+			})
+		}
+	}
+
+	// Request body
+	if operation.RequestBody == nil || base.ContentType == "" {
+		return cases
+	}
+	if operation.RequestBody.Required {
+		add("missing required request body", func(tc *model.TestCase) {
+			tc.ContentType = ""
+			tc.RequestBody = nil
+		})
+	}
+	schema := operation.RequestBody.Content[base.ContentType].Schema
+	textual := !isJSONMediaType(base.ContentType)
+	invalid := func(s domain.Schema, value interface{}, pointer string) []variant {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return prefixed(pointer, invalidValues(s, textual, g.maxSize()))
+		}
+		var variants []variant
+		for _, name := range sortedKeys(obj) {
+			if containsString(s.Required, name) {
+				variants = append(variants, variant{
+					description: "missing required property " + pointer + "/" + name,
+					value:       withProperty(obj, name, nil, true),
+				})
+			}
+		}
+		return variants
+	}
+	for _, mutation := range bodyVariants(schema, base.RequestBody, "", 0, invalid) {
+		mutation := mutation
+		add("body "+mutation.description, func(tc *model.TestCase) {
+			tc.RequestBody = mutation.value
+		})
+	}
+	return cases
+}
+
+// deriveCase copies a happy-path case for a variant. Cases with an invalid
+// request are marked negative and expect a 4xx.
+func deriveCase(base model.TestCase, description string, valid bool) model.TestCase {
+	testCase := base
+	testCase.Name = fmt.Sprintf("%s %s: %s", base.Method, base.Path, description)
+	testCase.Description = description
+	testCase.Params = append([]model.Param(nil), base.Params...)
+	if !valid {
+		testCase.Negative = true
+		testCase.ExpectedStatuses = clientErrorStatuses(base.DocumentedStatuses)
+	}
+	return testCase
+}
+
+// bodyVariants walks a request body alongside its schema, collecting the
+// variants leaf returns for each value, each applied to a copy of the whole
+// body. pointer locates value within the body.
+func bodyVariants(s domain.Schema, value interface{}, pointer string, depth int, leaf func(domain.Schema, interface{}, string) []variant) []variant {
+	s = s.MergeAllOf()
+	if depth > maxMutationDepth {
+		return nil
+	}
+
+	variants := leaf(s, value, pointer)
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return variants
+	}
+	for _, name := range sortedKeys(obj) {
+		prop, declared := s.Properties[name]
+		if !declared {
+			continue
+		}
+		for _, nested := range bodyVariants(prop, obj[name], pointer+"/"+name, depth+1, leaf) {
+			nested.value = withProperty(obj, name, nested.value, false)
+			variants = append(variants, nested)
+		}
+	}
+	return variants
+}
+
+// prefixed adds the body location to variant descriptions
+func prefixed(pointer string, variants []variant) []variant {
+	for i := range variants {
+		variants[i].description = location(pointer) + variants[i].description
+	}
+	return variants
+}
+
+func sortedKeys(obj map[string]interface{}) []string {
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// withProperty returns a shallow copy of obj with one property replaced or
+// removed
+func withProperty(obj map[string]interface{}, name string, value interface{}, remove bool) map[string]interface{} {
+	out := make(map[string]interface{}, len(obj))
+	for k, v := range obj {
+		out[k] = v
+	}
+	if remove {
+		delete(out, name)
+	} else {
+		out[name] = value
+	}
+	return out
+}
+
+// invalidValues lists values that break a primitive schema's type or
+// constraints. Schemas that accept anything yield none. textual is set when
+// the value is sent as text, where any value is a valid string. Length
+// cases needing a string longer than maxSize are left out.
+func invalidValues(s domain.Schema, textual bool, maxSize int) []variant {
+	s = s.MergeAllOf()
+	if len(s.Alternatives()) > 0 || s.False {
+		return nil
+	}
+
+	var values []variant
+	add := func(description string, value interface{}) {
+		values = append(values, variant{description: description, value: value})
+	}
+
+	if wrong, ok := wrongType(s, textual); ok {
+		add("of the wrong type (want "+s.Type+")", wrong)
+	}
+
+	switch s.Type {
+	case "integer", "number":
+		integer := s.Type == "integer"
+		if integer {
+			add("not an integer", 1.5)
+		}
+		if s.Minimum != nil {
+			below := *s.Minimum - 1
+			if s.ExclusiveMinimum {
+				below = *s.Minimum
+			}
+			add(fmt.Sprintf("below minimum %v", *s.Minimum), numeric(math.Floor(below), integer, below))
+		}
+		if s.Maximum != nil {
+			above := *s.Maximum + 1
+			if s.ExclusiveMaximum {
+				above = *s.Maximum
+			}
+			add(fmt.Sprintf("above maximum %v", *s.Maximum), numeric(math.Ceil(above), integer, above))
+		}
+		if s.MultipleOf != nil && *s.MultipleOf > 0 {
+			// 1.5 times the factor is never a multiple of it
+			v := *s.MultipleOf * 1.5
+			if !integer || v == math.Trunc(v) {
+				add(fmt.Sprintf("not a multiple of %v", *s.MultipleOf), numeric(v, integer, v))
+			}
+		}
+	case "string", "":
+		if s.MinLength != nil && *s.MinLength > 0 && *s.MinLength <= maxSize+1 {
+			add(fmt.Sprintf("shorter than minLength %d", *s.MinLength), strings.Repeat("a", *s.MinLength-1))
+		}
+		if s.MaxLength != nil && *s.MaxLength < maxSize {
+			add(fmt.Sprintf("longer than maxLength %d", *s.MaxLength), strings.Repeat("a", *s.MaxLength+1))
+		}
+		if s.Pattern != "" {
+			if v, ok := nonMatching(s.Pattern); ok {
+				add("not matching pattern "+s.Pattern, v)
+			}
+		}
+		if invalid, ok := invalidFormats[s.Format]; ok {
+			add("not a valid "+s.Format, invalid)
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		add("outside its enum", outsideEnum(s.Enum))
+	}
+	return values
+}
+
+// wrongType returns a value of a type the schema does not allow
+func wrongType(s domain.Schema, textual bool) (interface{}, bool) {
+	candidates := []struct {
+		jsonType string
+		value    interface{}
+	}{
+		{"string", "wrong-type"},
+		{"number", 12345.5},
+		{"boolean", true},
+	}
+	if s.Type == "" || textual && s.AllowsType("string") {
+		return nil, false
+	}
+	for _, candidate := range candidates {
+		allowed := s.AllowsType(candidate.jsonType) || candidate.jsonType == "number" && s.AllowsType("integer")
+		if !allowed {
+			return candidate.value, true
+		}
+	}
+	return nil, false
+}
+
+// numeric picks the whole or fractional form of a value
+func numeric(whole float64, integer bool, v float64) interface{} {
+	if integer {
+		return int64(whole)
+	}
+	return v
+}
+
+// nonMatching finds a string that does not match pattern
+func nonMatching(pattern string) (string, bool) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", false
+	}
+	for _, candidate := range []string{"", "!", "~~ invalid ~~", "0", "a"} {
+		if !re.MatchString(candidate) {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// outsideEnum returns a value none of the enum members equal
+func outsideEnum(enum []interface{}) interface{} {
+	numbers := true
+	max := math.Inf(-1)
+	for _, member := range enum {
+		switch v := member.(type) {
+		case float64:
+			max = math.Max(max, v)
+		case int:
+			max = math.Max(max, float64(v))
+		case int64:
+			max = math.Max(max, float64(v))
+		default:
+			numbers = false
+		}
+	}
+	if numbers {
+		return max + 1
+	}
+
+	value := "not-in-enum"
+	for containsValue(enum, value) {
+		value += "-x"
+	}
+	return value
+}
+
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, candidate := range values {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
+
+// location prefixes a description with the body pointer it applies to
+func location(pointer string) string {
+	if pointer == "" {
+		return ""
+	}
+	return pointer + " "
+}
+
+// findParameter returns the declared parameter with the given name and
+// location
+func findParameter(params []domain.Parameter, name, in string) domain.Parameter {
+	for _, param := range params {
+		if param.Name == name && param.In == in {
+			return param
+		}
+	}
+	return domain.Parameter{}
+}
+
+// clientErrorStatuses lists the responses a rejected request should
+// produce: any documented 4xx response, or another 4xx code
+func clientErrorStatuses(documented []string) []string {
+	var statuses []string
+	for _, status := range documented {
+		if strings.HasPrefix(status, "4") && !strings.EqualFold(status, "4XX") {
+			statuses = append(statuses, status)
+		}
+	}
+	return append(statuses, "4XX")
+}
+
+// isJSONMediaType reports whether a media type carries JSON
+func isJSONMediaType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
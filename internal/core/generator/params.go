@@ -0,0 +1,15 @@
+package generator
+
+import "github.com/BarneyRubble12/specdrill/internal/core/domain"
+
+// ParameterValue generates a value for a parameter, preferring the examples
+// documented on the parameter itself over ones derived from its schema
+func (g *Generator) ParameterValue(param domain.Parameter) interface{} {
+	if param.Example != nil {
+		return param.Example
+	}
+	if example, ok := firstExample(param.Examples); ok {
+		return example
+	}
+	return g.Value(param.Schema)
+}
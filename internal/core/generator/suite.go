@@ -0,0 +1,49 @@
+package generator
+
+import (
+	"sort"
+
+	"github.com/BarneyRubble12/specdrill/internal/core/domain"
+	"github.com/BarneyRubble12/specdrill/internal/core/model"
+)
+
+// GenerateSuite builds the test suite for a spec: the happy-path case for
+// every operation, in path and method order, followed by that operation's
+// negative, boundary and combination cases when the options ask for them
+func (g *Generator) GenerateSuite(spec *domain.APISpec) model.TestSuite {
+	suite := model.TestSuite{
+		Name:    spec.Info.Title,
+		BaseURL: spec.BaseURL,
+	}
+
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		pathItem := spec.Paths[path]
+		for _, method := range domain.Methods {
+			if pathItem.Operation(method) == nil {
+				continue
+			}
+
+			suite.TestCases = append(suite.TestCases, g.CreateTestCase(method, path, pathItem))
+			if g.options.Negative {
+				suite.TestCases = append(suite.TestCases, g.NegativeCases(method, path, pathItem)...)
+			}
+			if g.options.Boundary {
+				suite.TestCases = append(suite.TestCases, g.BoundaryCases(method, path, pathItem)...)
+			}
+			if g.options.Combinations {
+				cases, coverage := g.CombinationCases(method, path, pathItem)
+				if len(cases) > 0 {
+					suite.TestCases = append(suite.TestCases, cases...)
+					suite.Coverage = append(suite.Coverage, coverage)
+				}
+			}
+		}
+	}
+	return suite
+}
@@ -0,0 +1,433 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+
+	"github.com/BarneyRubble12/specdrill/internal/core/domain"
+)
+
+// maxDepth bounds how far nested objects and arrays are expanded
+const maxDepth = 8
+
+// formatExamples are representative values for well-known string formats
+var formatExamples = map[string]string{
+	"date-time": "2024-01-01T00:00:00Z",
+	"date":      "2024-01-01",
+	"time":      "12:00:00",
+	"email":     "user@example.com",
+	"uuid":      "123e4567-e89b-12d3-a456-426614174000",
+	"uri":       "https://example.com",
+	"url":       "https://example.com",
+	"hostname":  "example.com",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"byte":      "c3BlY2RyaWxs",
+	"binary":    "specdrill",
+	"password":  "Passw0rd!",
+}
+
+// Value synthesises a value that satisfies schema. Documented values are
+// preferred (const, example, examples, default, enum); otherwise the value
+// is built from the type, format and constraints. Read-only properties are
+// left out since the value is meant to be sent in a request.
+func (g *Generator) Value(schema domain.Schema) interface{} {
+	v, _ := g.value(schema, 0, 0)
+	return v
+}
+
+// value generates a value for schema. index varies the generated value so
+// that unique array items can be produced; ok is false when no value could
+// be generated, e.g. for recursive references or the false schema.
+func (g *Generator) value(s domain.Schema, depth, index int) (interface{}, bool) {
+	v, ok := g.generate(s, depth, index)
+	// Step past values ruled out by "not"; a few variations are enough for
+	// the enum and const exclusions specs use in practice
+	for attempt := 1; ok && s.Not != nil && excludes(*s.Not, v) && attempt <= 3; attempt++ {
+		v, ok = g.generate(s, depth, index+attempt)
+	}
+	return v, ok
+}
+
+// excludes reports whether v is one of the values a "not" schema names
+func excludes(not domain.Schema, v interface{}) bool {
+	if not.HasConst && reflect.DeepEqual(not.Const, v) {
+		return true
+	}
+	for _, candidate := range not.Enum {
+		if reflect.DeepEqual(candidate, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Generator) generate(s domain.Schema, depth, index int) (interface{}, bool) {
+	if s.False || s.IsRecursive() || depth > maxDepth {
+		return nil, false
+	}
+	if len(s.AllOf) > 0 {
+		return g.value(s.MergeAllOf(), depth, index)
+	}
+	if alts := s.Alternatives(); len(alts) > 0 {
+		return g.alternative(s, alts, depth, index)
+	}
+
+	if v, ok := documentedValue(s, index); ok {
+		return v, true
+	}
+
+	switch schemaType(s) {
+	case "object":
+		return g.object(s, depth), true
+	case "array":
+		return g.array(s, depth), true
+	case "integer":
+		return int64(numberValue(s, true, index)), true
+	case "number":
+		return numberValue(s, false, index), true
+	case "boolean":
+		return index%2 == 0, true
+	case "null":
+		return nil, true
+	}
+	return stringValue(s, index), true
+}
+
+// documentedValue returns a value the spec itself provides for the schema
+func documentedValue(s domain.Schema, index int) (interface{}, bool) {
+	if s.HasConst {
+		return s.Const, true
+	}
+	if index == 0 {
+		if s.Example != nil {
+			return s.Example, true
+		}
+		for _, example := range s.Examples {
+			if example != nil {
+				return example, true
+			}
+		}
+		if s.Default != nil {
+			return s.Default, true
+		}
+	}
+	if len(s.Enum) > 0 {
+		return s.Enum[index%len(s.Enum)], true
+	}
+	return nil, false
+}
+
+// schemaType returns the type to generate, inferring it from the keywords
+// present when none is declared
+func schemaType(s domain.Schema) string {
+	switch {
+	case s.Type != "":
+		return s.Type
+	case len(s.Properties) > 0 || s.AdditionalProperties != nil:
+		return "object"
+	case s.Items != nil || len(s.PrefixItems) > 0:
+		return "array"
+	case s.Minimum != nil || s.Maximum != nil || s.MultipleOf != nil:
+		return "number"
+	}
+	return "string"
+}
+
+// alternative generates a value for the first usable oneOf/anyOf member,
+// setting the discriminator property so it selects that member
+func (g *Generator) alternative(s domain.Schema, alts []domain.Schema, depth, index int) (interface{}, bool) {
+	for _, alt := range alts {
+		base := s
+		base.OneOf, base.AnyOf, base.Discriminator = nil, nil, nil
+		v, ok := g.value(mergeInto(base, alt), depth, index)
+		if !ok {
+			continue
+		}
+		if obj, isObj := v.(map[string]interface{}); isObj && s.Discriminator != nil {
+			if name := s.DiscriminatorValue(alt); name != "" {
+				obj[s.Discriminator.PropertyName] = name
+			}
+		}
+		return v, true
+	}
+	return nil, false
+}
+
+// mergeInto combines a schema's own keywords with one of its alternatives
+func mergeInto(base, alt domain.Schema) domain.Schema {
+	if reflect.DeepEqual(base, domain.Schema{}) {
+		return alt
+	}
+	return domain.Schema{AllOf: []domain.Schema{base, alt}}.MergeAllOf()
+}
+
+func (g *Generator) object(s domain.Schema, depth int) map[string]interface{} {
+	obj := make(map[string]interface{})
+
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop := s.Properties[name]
+		required := containsString(s.Required, name)
+		if prop.ReadOnly || (prop.Deprecated && !required) {
+			continue
+		}
+		if v, ok := g.value(prop, depth+1, 0); ok {
+			obj[name] = v
+		}
+	}
+
+	if len(s.Properties) == 0 && s.AdditionalProperties != nil {
+		if v, ok := g.value(*s.AdditionalProperties, depth+1, 0); ok {
+			obj["key"] = v
+		}
+	}
+	return obj
+}
+
+func (g *Generator) array(s domain.Schema, depth int) []interface{} {
+	count := 1
+	if s.MinItems != nil && *s.MinItems > count {
+		count = *s.MinItems
+	}
+	if count < len(s.PrefixItems) {
+		count = len(s.PrefixItems)
+	}
+	if s.MaxItems != nil && *s.MaxItems < count {
+		count = *s.MaxItems
+	}
+
+	items := make([]interface{}, 0, count)
+	for i := 0; i < count; i++ {
+		var item domain.Schema
+		switch {
+		case i < len(s.PrefixItems):
+			item = s.PrefixItems[i]
+		case s.Items != nil:
+			item = *s.Items
+		}
+		index := 0
+		if s.UniqueItems {
+			index = i
+		}
+		v, ok := g.value(item, depth+1, index)
+		if !ok {
+			break
+		}
+		items = append(items, v)
+	}
+	return items
+}
+
+// numericBounds returns the inclusive range allowed by a numeric schema.
+// Exclusive bounds are moved inwards by one for integers and by the
+// smallest representable step for numbers.
+func numericBounds(s domain.Schema, integer bool) (float64, float64) {
+	lo, hi := math.Inf(-1), math.Inf(1)
+	if s.Minimum != nil {
+		lo = *s.Minimum
+		if integer {
+			lo = math.Ceil(lo)
+		}
+		if s.ExclusiveMinimum {
+			if integer && lo == *s.Minimum {
+				lo++
+			} else if !integer {
+				lo = math.Nextafter(lo, math.Inf(1))
+			}
+		}
+	}
+	if s.Maximum != nil {
+		hi = *s.Maximum
+		if integer {
+			hi = math.Floor(hi)
+		}
+		if s.ExclusiveMaximum {
+			if integer && hi == *s.Maximum {
+				hi--
+			} else if !integer {
+				hi = math.Nextafter(hi, math.Inf(-1))
+			}
+		}
+	}
+	return lo, hi
+}
+
+// numberValue picks a value inside the schema's bounds, preferring small
+// positive numbers and honouring multipleOf
+func numberValue(s domain.Schema, integer bool, index int) float64 {
+	lo, hi := numericBounds(s, integer)
+
+	v := float64(1 + index)
+	if v < lo {
+		v = lo + float64(index)
+	}
+	if v > hi {
+		v = hi
+	}
+
+	if s.MultipleOf != nil && *s.MultipleOf > 0 {
+		m := *s.MultipleOf
+		v = math.Ceil(v/m) * m
+		if v > hi {
+			v = math.Floor(hi/m) * m
+		}
+		if v < lo {
+			v = math.Ceil(lo/m) * m
+		}
+	}
+	if integer {
+		v = math.Round(v)
+	}
+	return v
+}
+
+// stringValue builds a string honouring format, pattern and length limits
+func stringValue(s domain.Schema, index int) string {
+	if example, ok := formatExamples[s.Format]; ok {
+		return example
+	}
+
+	minLength, maxLength := 0, math.MaxInt32
+	if s.MinLength != nil {
+		minLength = *s.MinLength
+	}
+	if s.MaxLength != nil {
+		maxLength = *s.MaxLength
+	}
+
+	if s.Pattern != "" {
+		if v, ok := patternString(s.Pattern, minLength, maxLength); ok {
+			return v
+		}
+	}
+
+	v := "string"
+	if index > 0 {
+		v = fmt.Sprintf("string%d", index)
+	}
+	return fitLength(v, minLength, maxLength)
+}
+
+// fitLength pads or truncates v to lie within the given rune lengths
+func fitLength(v string, minLength, maxLength int) string {
+	runes := []rune(v)
+	for len(runes) < minLength {
+		runes = append(runes, 'a')
+	}
+	if len(runes) > maxLength {
+		runes = runes[:maxLength]
+	}
+	return string(runes)
+}
+
+// patternString produces a string matching pattern whose length lies
+// within the limits, trying increasing repetition counts for the pattern's
+// quantifiers until one fits
+func patternString(pattern string, minLength, maxLength int) (string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	matcher, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", false
+	}
+	re = re.Simplify()
+
+	for reps := 1; reps <= minLength+1; reps++ {
+		var b strings.Builder
+		writePattern(&b, re, reps)
+		v := b.String()
+		n := len([]rune(v))
+		if n >= minLength && n <= maxLength && matcher.MatchString(v) {
+			return v, true
+		}
+		if n > maxLength {
+			break
+		}
+	}
+	return "", false
+}
+
+// writePattern writes one string matched by re, repeating open-ended
+// quantifiers reps times
+func writePattern(b *strings.Builder, re *syntax.Regexp, reps int) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		b.WriteRune(classRune(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteRune('a')
+	case syntax.OpCapture:
+		writePattern(b, re.Sub[0], reps)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			writePattern(b, sub, reps)
+		}
+	case syntax.OpAlternate:
+		writePattern(b, re.Sub[0], reps)
+	case syntax.OpStar, syntax.OpPlus:
+		for i := 0; i < reps; i++ {
+			writePattern(b, re.Sub[0], reps)
+		}
+	case syntax.OpQuest:
+		if reps > 1 {
+			writePattern(b, re.Sub[0], reps)
+		}
+	case syntax.OpRepeat:
+		n := reps
+		if n < re.Min {
+			n = re.Min
+		}
+		if re.Max >= 0 && n > re.Max {
+			n = re.Max
+		}
+		for i := 0; i < n; i++ {
+			writePattern(b, re.Sub[0], reps)
+		}
+	}
+}
+
+// classRune picks a readable rune from a character class, given as pairs
+// of inclusive ranges
+func classRune(ranges []rune) rune {
+	for _, preferred := range []rune{'a', 'A', '0'} {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			if ranges[i] <= preferred && preferred <= ranges[i+1] {
+				return preferred
+			}
+		}
+	}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1] && r < ranges[i]+128; r++ {
+			if r > ' ' && r < 0x7f {
+				return r
+			}
+		}
+	}
+	if len(ranges) > 0 {
+		return ranges[0]
+	}
+	return 'a'
+}
+
+func containsString(values []string, v string) bool {
+	for _, candidate := range values {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"sort"
+	"strings"
+)
+
+// Curl renders the logged request as a curl command that sends it again
+func (l TestCaseLog) Curl() string {
+	url := l.URL
+	if url == "" {
+		url = l.Endpoint
+	}
+	parts := []string{"curl", "-X", l.Method, shellQuote(url)}
+
+	names := make([]string, 0, len(l.RequestHeaders))
+	for name := range l.RequestHeaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		parts = append(parts, "-H", shellQuote(name+": "+l.RequestHeaders[name]))
+	}
+
+	if l.RequestBody != "" {
+		parts = append(parts, "--data-binary", shellQuote(l.RequestBody))
+	}
+	return strings.Join(parts, " ")
+}
+
+// shellQuote wraps s in single quotes for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
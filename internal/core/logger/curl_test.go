@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurl(t *testing.T) {
+	tests := []struct {
+		name string
+		log  TestCaseLog
+		want string
+	}{
+		{
+			name: "Request without a body",
+			log: TestCaseLog{
+				Method:   "GET",
+				Endpoint: "http://localhost/pets",
+				URL:      "http://localhost/pets?limit=10",
+				RequestHeaders: map[string]string{
+					"X-Request-Id": "abc",
+					"Accept":       "application/json",
+				},
+			},
+			want: "curl -X GET 'http://localhost/pets?limit=10' -H 'Accept: application/json' -H 'X-Request-Id: abc'",
+		},
+		{
+			name: "Body with quotes",
+			log: TestCaseLog{
+				Method:         "POST",
+				Endpoint:       "http://localhost/pets",
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				RequestBody:    `{"name":"Rex's"}`,
+			},
+			want: `curl -X POST 'http://localhost/pets' -H 'Content-Type: application/json' --data-binary '{"name":"Rex'\''s"}'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.log.Curl())
+		})
+	}
+}
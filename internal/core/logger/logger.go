@@ -21,6 +21,7 @@ func init() {
 type TestCaseLog struct {
 	Name           string            `json:"name"`
 	Endpoint       string            `json:"endpoint"`
+	URL            string            `json:"url"` // Endpoint with the query string as sent
 	Method         string            `json:"method"`
 	PathParams     map[string]string `json:"path_params"`
 	QueryParams    map[string]string `json:"query_params"`
@@ -36,6 +37,7 @@ func LogTestCase(testLog TestCaseLog) {
 	fields := logrus.Fields{
 		"name":            testLog.Name,
 		"endpoint":        testLog.Endpoint,
+		"url":             testLog.URL,
 		"method":          testLog.Method,
 		"path_params":     testLog.PathParams,
 		"query_params":    testLog.QueryParams,
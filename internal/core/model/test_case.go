@@ -2,34 +2,154 @@ package model
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/BarneyRubble12/specdrill/internal/core/logger"
 )
 
 // TestCase represents a single API test case
 type TestCase struct {
-	Name           string
-	Method         string
-	Path           string
-	RequestBody    interface{}
-	ExpectedStatus int
-	Description    string
+	Name        string      `json:"name"`
+	Method      string      `json:"method"`
+	Path        string      `json:"path"` // Path template, e.g. /pets/{petId}
+	Params      []Param     `json:"params,omitempty"`
+	RequestBody interface{} `json:"request_body,omitempty"`
+	ContentType string      `json:"content_type,omitempty"` // Media type of RequestBody; empty when no body is sent
+	// ExpectedStatuses are the response keys (exact codes, ranges such as
+	// 2XX, or default) a correct API may answer this case with
+	ExpectedStatuses []string `json:"expected_statuses"`
+	// DocumentedStatuses are all response keys the operation documents
+	DocumentedStatuses []string `json:"documented_statuses,omitempty"`
+	// Negative marks cases that deliberately break the request contract
+	Negative    bool   `json:"negative,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// MatchStatus returns the documented response key that covers an HTTP status
+// code and whether that key is one of the expected ones. An exact code takes
+// precedence over a range, and a range over default, which only covers
+// codes the operation does not document otherwise. An expected range also
+// accepts codes that are undocumented or only covered by default.
+func (tc TestCase) MatchStatus(code int) (string, bool) {
+	documented := tc.DocumentedStatuses
+	if len(documented) == 0 {
+		documented = tc.ExpectedStatuses
+	}
+
+	key := ""
+	for _, candidate := range documented {
+		if specificity(candidate, code) > specificity(key, code) {
+			key = candidate
+		}
+	}
+	for _, expected := range tc.ExpectedStatuses {
+		if key != "" && strings.EqualFold(expected, key) {
+			return key, true
+		}
+	}
+
+	if key == "" || key == "default" {
+		for _, expected := range tc.ExpectedStatuses {
+			if specificity(expected, code) == 2 {
+				return expected, true
+			}
+		}
+	}
+	return key, false
+}
+
+// specificity ranks how closely a response key matches code: 3 for the
+// exact code, 2 for its range, 1 for default and 0 for no match
+func specificity(key string, code int) int {
+	status := strconv.Itoa(code)
+	switch {
+	case key == status:
+		return 3
+	case len(key) == 3 && strings.EqualFold(key[1:], "XX") && key[0] == status[0]:
+		return 2
+	case key == "default":
+		return 1
+	}
+	return 0
+}
+
+// Param is a parameter value to send with a test case
+type Param struct {
+	Name    string      `json:"name"`
+	In      string      `json:"in"`              // path, query, header or cookie
+	Style   string      `json:"style,omitempty"` // OpenAPI serialisation style, e.g. form or simple
+	Explode bool        `json:"explode,omitempty"`
+	Value   interface{} `json:"value"`
 }
 
 // TestResult represents the result of executing a test case
 type TestResult struct {
 	TestCase    TestCase
-	Success     bool
+	URL         string
 	StatusCode  int
-	Error       error
-	Response    *http.Response
-	Duration    int64 // in milliseconds
-	Message     string
+	Headers     http.Header
+	Body        string
+	IsValidJSON bool
+	// MatchedStatus is the documented response key covering StatusCode, or
+	// empty when the code is undocumented
+	MatchedStatus string
+	// Violations lists where the headers and body depart from the
+	// documented response
+	Violations []Violation
+	// Drift lists undocumented behaviour found in drift mode
+	Drift []Violation
+	// Passed reports whether StatusCode is one of the expected statuses and
+	// the response matches its documentation
+	Passed bool
+	// Error is set when no response was received
+	Error    error
+	Duration int64 // in milliseconds
+	// Attempts lists every try at sending the request, the last one being
+	// judged; earlier ones were throttled and retried
+	Attempts []Attempt
+	// Request is the request as sent, with the response it got
+	Request logger.TestCaseLog
+}
+
+// Attempt is one try at sending a test case's request
+type Attempt struct {
+	StatusCode int    // Zero when no response was received
+	Error      string // Set when no response was received
+	Duration   int64  // in milliseconds
+	Wait       int64  // Milliseconds waited before the next attempt
 }
 
 // TestSuite represents a collection of test cases
 type TestSuite struct {
 	Name      string
-	BaseURL   string
+	BaseURL   string // Overrides the spec's base URL when set
 	TestCases []TestCase
+	// Coverage reports the parameter combinations the suite exercises per
+	// operation
+	Coverage []Coverage
+}
+
+// Coverage reports how many t-way combinations of an operation's parameter
+// values a set of combination cases exercises
+type Coverage struct {
+	Method   string
+	Path     string
+	Strength int // Number of parameters combined, 2 for pairwise
+	Covered  int // Combinations of Strength parameter values exercised
+	Total    int // All combinations of Strength parameter values
+	Cases    int
+	// Exhaustive is the number of cases needed to try every combination of
+	// all parameter values
+	Exhaustive int
+}
+
+// Percent returns the share of combinations exercised
+func (c Coverage) Percent() float64 {
+	if c.Total == 0 {
+		return 100
+	}
+	return 100 * float64(c.Covered) / float64(c.Total)
 }
 
 // TestSummary represents the summary of test execution
@@ -39,4 +159,8 @@ type TestSummary struct {
 	FailedTests int
 	Duration    int64 // in milliseconds
 	Results     []TestResult
-} 
\ No newline at end of file
+	// Skipped counts cases not run, or cancelled, because the run was
+	// interrupted
+	Skipped     int
+	Interrupted bool
+}
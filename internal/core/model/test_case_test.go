@@ -0,0 +1,102 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		testCase   TestCase
+		code       int
+		wantKey    string
+		wantPassed bool
+	}{
+		{
+			name:       "Exact code",
+			testCase:   TestCase{ExpectedStatuses: []string{"201"}, DocumentedStatuses: []string{"201", "400"}},
+			code:       201,
+			wantKey:    "201",
+			wantPassed: true,
+		},
+		{
+			name:     "Other success code",
+			testCase: TestCase{ExpectedStatuses: []string{"201"}, DocumentedStatuses: []string{"201", "400"}},
+			code:     200,
+		},
+		{
+			name:     "Documented error",
+			testCase: TestCase{ExpectedStatuses: []string{"201"}, DocumentedStatuses: []string{"201", "404"}},
+			code:     404,
+			wantKey:  "404",
+		},
+		{
+			name:       "Range",
+			testCase:   TestCase{ExpectedStatuses: []string{"2XX"}, DocumentedStatuses: []string{"2XX", "4XX"}},
+			code:       204,
+			wantKey:    "2XX",
+			wantPassed: true,
+		},
+		{
+			name:       "Lower case range",
+			testCase:   TestCase{ExpectedStatuses: []string{"2xx"}, DocumentedStatuses: []string{"2xx"}},
+			code:       202,
+			wantKey:    "2xx",
+			wantPassed: true,
+		},
+		{
+			name:     "Exact code beats range",
+			testCase: TestCase{ExpectedStatuses: []string{"2XX"}, DocumentedStatuses: []string{"2XX", "204"}},
+			code:     204,
+			wantKey:  "204",
+		},
+		{
+			name:       "Default covers undocumented codes",
+			testCase:   TestCase{ExpectedStatuses: []string{"default"}, DocumentedStatuses: []string{"404", "default"}},
+			code:       200,
+			wantKey:    "default",
+			wantPassed: true,
+		},
+		{
+			name:     "Default does not cover documented codes",
+			testCase: TestCase{ExpectedStatuses: []string{"default"}, DocumentedStatuses: []string{"404", "default"}},
+			code:     404,
+			wantKey:  "404",
+		},
+		{
+			name:       "Undocumented code in expected range",
+			testCase:   TestCase{ExpectedStatuses: []string{"2XX"}, DocumentedStatuses: []string{"404"}},
+			code:       200,
+			wantKey:    "2XX",
+			wantPassed: true,
+		},
+		{
+			name:       "Expected range beats default",
+			testCase:   TestCase{ExpectedStatuses: []string{"400", "4XX"}, DocumentedStatuses: []string{"200", "400", "default"}},
+			code:       422,
+			wantKey:    "4XX",
+			wantPassed: true,
+		},
+		{
+			name:     "Documented success for a rejected request",
+			testCase: TestCase{ExpectedStatuses: []string{"400", "4XX"}, DocumentedStatuses: []string{"200", "400", "default"}},
+			code:     200,
+			wantKey:  "200",
+		},
+		{
+			name:     "Undocumented code",
+			testCase: TestCase{ExpectedStatuses: []string{"200"}, DocumentedStatuses: []string{"200"}},
+			code:     500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, passed := tt.testCase.MatchStatus(tt.code)
+			assert.Equal(t, tt.wantKey, key)
+			assert.Equal(t, tt.wantPassed, passed)
+		})
+	}
+}
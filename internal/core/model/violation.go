@@ -0,0 +1,28 @@
+package model
+
+import "fmt"
+
+// Violation describes one way a response departs from its documented contract
+type Violation struct {
+	Header   string // Response header the violation concerns; empty for the body
+	Pointer  string // JSON pointer to the offending value; empty for the whole body or header
+	Keyword  string // Schema keyword that failed, e.g. type or required, or status
+	Expected string
+	Actual   string
+}
+
+// String formats the violation for reports
+func (v Violation) String() string {
+	if v.Keyword == "status" {
+		// The status code belongs to the response as a whole
+		return fmt.Sprintf("status: expected %s, got %s", v.Expected, v.Actual)
+	}
+	pointer := v.Pointer
+	switch {
+	case v.Header != "":
+		pointer = "header " + v.Header + pointer
+	case pointer == "":
+		pointer = "(root)"
+	}
+	return fmt.Sprintf("%s: %s: expected %s, got %s", pointer, v.Keyword, v.Expected, v.Actual)
+}
@@ -0,0 +1,452 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// format identifies the serialisation of a specification document
+type format int
+
+const (
+	formatJSON format = iota
+	formatYAML
+)
+
+// ParseError reports a problem decoding a specification document together
+// with the position of the offending node
+type ParseError struct {
+	Line    int
+	Column  int
+	Pointer string // JSON pointer of the offending node, if known
+	Err     error
+}
+
+func (e *ParseError) Error() string {
+	var b strings.Builder
+	if e.Line > 0 {
+		fmt.Fprintf(&b, "line %d", e.Line)
+		if e.Column > 0 {
+			fmt.Fprintf(&b, ", column %d", e.Column)
+		}
+	}
+	if e.Pointer != "" {
+		if b.Len() > 0 {
+			b.WriteString(" ")
+		}
+		fmt.Fprintf(&b, "(%s)", e.Pointer)
+	}
+	if b.Len() > 0 {
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// position is the line and column of a node in the source document
+type position struct {
+	Line   int
+	Column int
+}
+
+// positions maps JSON pointers of decoded nodes to their source positions
+type positions map[string]position
+
+// detectFormat works out whether a document is JSON or YAML. The file
+// extension wins, then the Content-Type of a remote fetch, and finally the
+// content itself is sniffed.
+func detectFormat(location, contentType string, data []byte) format {
+	switch strings.ToLower(path.Ext(location)) {
+	case ".json":
+		return formatJSON
+	case ".yaml", ".yml":
+		return formatYAML
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch {
+		case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+			return formatJSON
+		case strings.Contains(mediaType, "yaml"):
+			return formatYAML
+		}
+	}
+
+	trimmed := bytes.TrimLeft(data, " \t\r\n\ufeff")
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return formatJSON
+	}
+	return formatYAML
+}
+
+// decodeDocument decodes raw document bytes into a generic tree of maps,
+// slices and scalars, recording where every node came from
+func decodeDocument(data []byte, f format) (interface{}, positions, error) {
+	if f == formatJSON {
+		return decodeJSON(data)
+	}
+	return decodeYAML(data)
+}
+
+// jsonDecoder builds a generic tree from JSON while tracking node positions
+type jsonDecoder struct {
+	dec   *json.Decoder
+	data  []byte
+	lines []int
+	pos   positions
+}
+
+func decodeJSON(data []byte) (interface{}, positions, error) {
+	d := &jsonDecoder{
+		dec:   json.NewDecoder(bytes.NewReader(data)),
+		data:  data,
+		lines: lineStarts(data),
+		pos:   positions{},
+	}
+	d.dec.UseNumber()
+
+	v, err := d.value("")
+	if err != nil {
+		return nil, nil, d.wrap(err)
+	}
+	if _, err := d.dec.Token(); err != io.EOF {
+		p := d.position(d.valueStart())
+		return nil, nil, &ParseError{Line: p.Line, Column: p.Column, Err: errors.New("unexpected data after top-level value")}
+	}
+	return v, d.pos, nil
+}
+
+func (d *jsonDecoder) value(ptr string) (interface{}, error) {
+	d.pos[ptr] = d.position(d.valueStart())
+
+	tok, err := d.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+
+	switch delim {
+	case '{':
+		obj := make(map[string]interface{})
+		for d.dec.More() {
+			keyTok, err := d.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := keyTok.(string)
+			v, err := d.value(ptr + "/" + escapePointer(key))
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = v
+		}
+		if _, err := d.dec.Token(); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	case '[':
+		arr := make([]interface{}, 0)
+		for i := 0; d.dec.More(); i++ {
+			v, err := d.value(ptr + "/" + strconv.Itoa(i))
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		if _, err := d.dec.Token(); err != nil {
+			return nil, err
+		}
+		return arr, nil
+	}
+	return nil, fmt.Errorf("unexpected delimiter %q", delim)
+}
+
+// valueStart returns the offset of the next value, skipping separators
+func (d *jsonDecoder) valueStart() int {
+	off := int(d.dec.InputOffset())
+	for off < len(d.data) && strings.IndexByte(" \t\r\n:,", d.data[off]) >= 0 {
+		off++
+	}
+	return off
+}
+
+func (d *jsonDecoder) position(offset int) position {
+	line := lineOf(d.lines, offset)
+	return position{Line: line + 1, Column: offset - d.lines[line] + 1}
+}
+
+func (d *jsonDecoder) wrap(err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		p := d.position(int(syntaxErr.Offset))
+		return &ParseError{Line: p.Line, Column: p.Column, Err: err}
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		p := d.position(len(d.data))
+		return &ParseError{Line: p.Line, Column: p.Column, Err: io.ErrUnexpectedEOF}
+	}
+	return err
+}
+
+// lineStarts returns the byte offset at which every line begins
+func lineStarts(data []byte) []int {
+	starts := []int{0}
+	for i, c := range data {
+		if c == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// lineOf returns the zero-based line containing offset
+func lineOf(starts []int, offset int) int {
+	lo, hi := 0, len(starts)-1
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if starts[mid] <= offset {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return lo
+}
+
+// yamlErrorPrefix matches the position yaml.v3 puts at the start of its
+// syntax errors, e.g. "yaml: line 7: "
+var yamlErrorPrefix = regexp.MustCompile(`^yaml: (?:line (\d+)(?:, column (\d+))?: )?`)
+
+func decodeYAML(data []byte) (interface{}, positions, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, nil, yamlError(err)
+	}
+	if len(root.Content) == 0 {
+		return nil, nil, &ParseError{Err: errors.New("document is empty")}
+	}
+
+	pos := positions{}
+	v, err := yamlValue(root.Content[0], "", pos)
+	if err != nil {
+		return nil, nil, err
+	}
+	return v, pos, nil
+}
+
+// yamlError turns a yaml.v3 error into a ParseError, moving the position
+// from the message into its fields so it is not reported twice
+func yamlError(err error) error {
+	m := yamlErrorPrefix.FindStringSubmatchIndex(err.Error())
+	if m == nil {
+		return &ParseError{Err: err}
+	}
+	msg := err.Error()
+	perr := &ParseError{Err: errors.New(msg[m[1]:])}
+	if m[2] >= 0 {
+		perr.Line, _ = strconv.Atoi(msg[m[2]:m[3]])
+	}
+	if m[4] >= 0 {
+		perr.Column, _ = strconv.Atoi(msg[m[4]:m[5]])
+	}
+	return perr
+}
+
+func yamlValue(n *yaml.Node, ptr string, pos positions) (interface{}, error) {
+	pos[ptr] = position{Line: n.Line, Column: n.Column}
+
+	switch n.Kind {
+	case yaml.AliasNode:
+		return yamlValue(n.Alias, ptr, pos)
+	case yaml.MappingNode:
+		obj := make(map[string]interface{})
+		if err := yamlMapping(n, ptr, pos, obj); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	case yaml.SequenceNode:
+		arr := make([]interface{}, 0, len(n.Content))
+		for i, item := range n.Content {
+			v, err := yamlValue(item, ptr+"/"+strconv.Itoa(i), pos)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, nil
+	case yaml.ScalarNode:
+		return yamlScalar(n)
+	}
+	return nil, &ParseError{Line: n.Line, Column: n.Column, Err: fmt.Errorf("unsupported YAML node kind %d", n.Kind)}
+}
+
+// yamlMapping copies the entries of a mapping node into obj, honouring
+// merge keys. Keys are always taken as strings so that unquoted status
+// codes such as 200 behave the same as they do in JSON.
+func yamlMapping(n *yaml.Node, ptr string, pos positions, obj map[string]interface{}) error {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		if k.Kind == yaml.AliasNode {
+			k = k.Alias
+		}
+
+		if k.Tag == "!!merge" {
+			sources := []*yaml.Node{v}
+			if v.Kind == yaml.SequenceNode {
+				sources = v.Content
+			}
+			for _, src := range sources {
+				if src.Kind == yaml.AliasNode {
+					src = src.Alias
+				}
+				if src.Kind != yaml.MappingNode {
+					return &ParseError{Line: src.Line, Column: src.Column, Err: errors.New("merge key value is not a mapping")}
+				}
+				if err := yamlMapping(src, ptr, pos, obj); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if k.Kind != yaml.ScalarNode {
+			return &ParseError{Line: k.Line, Column: k.Column, Err: errors.New("mapping key is not a scalar")}
+		}
+		child, err := yamlValue(v, ptr+"/"+escapePointer(k.Value), pos)
+		if err != nil {
+			return err
+		}
+		obj[k.Value] = child
+	}
+	return nil
+}
+
+func yamlScalar(n *yaml.Node) (interface{}, error) {
+	switch n.ShortTag() {
+	case "!!null":
+		return nil, nil
+	case "!!bool":
+		var b bool
+		err := n.Decode(&b)
+		return b, wrapYAMLScalar(n, err)
+	case "!!int":
+		var i int64
+		if err := n.Decode(&i); err != nil {
+			// Out of range integers are kept as floats rather than rejected
+			var f float64
+			err = n.Decode(&f)
+			return f, wrapYAMLScalar(n, err)
+		}
+		return i, nil
+	case "!!float":
+		var f float64
+		err := n.Decode(&f)
+		return f, wrapYAMLScalar(n, err)
+	}
+	return n.Value, nil
+}
+
+func wrapYAMLScalar(n *yaml.Node, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ParseError{Line: n.Line, Column: n.Column, Err: err}
+}
+
+// escapePointer escapes a key for use as a JSON pointer reference token
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// unescapePointer reverses escapePointer
+func unescapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+// locateField finds the JSON pointer of the node that encoding/json blamed
+// for an UnmarshalTypeError. The error only carries a dotted field path
+// without array indices, so keys containing dots and arrays are searched.
+func locateField(tree interface{}, field, jsonType string) (string, bool) {
+	if field == "" {
+		return "", false
+	}
+	return locateSegments(tree, strings.Split(field, "."), jsonType, "")
+}
+
+func locateSegments(node interface{}, segs []string, jsonType, ptr string) (string, bool) {
+	if len(segs) == 0 {
+		if jsonKind(node) == jsonType {
+			return ptr, true
+		}
+		// The offending value may be an element of an array at this path
+		if arr, ok := node.([]interface{}); ok {
+			for i, item := range arr {
+				if jsonKind(item) == jsonType {
+					return ptr + "/" + strconv.Itoa(i), true
+				}
+			}
+		}
+		return ptr, jsonType == ""
+	}
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for j := len(segs); j >= 1; j-- {
+			// Newer encoding/json versions escape keys like JSON pointer tokens
+			joined := strings.Join(segs[:j], ".")
+			for _, key := range []string{joined, unescapePointer(joined)} {
+				child, ok := n[key]
+				if !ok {
+					continue
+				}
+				if p, ok := locateSegments(child, segs[j:], jsonType, ptr+"/"+escapePointer(key)); ok {
+					return p, true
+				}
+			}
+		}
+	case []interface{}:
+		for i, item := range n {
+			if p, ok := locateSegments(item, segs, jsonType, ptr+"/"+strconv.Itoa(i)); ok {
+				return p, true
+			}
+		}
+	}
+	return "", false
+}
+
+// jsonKind names the JSON type of a generic value the way encoding/json
+// does in its error messages
+func jsonKind(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		return "number"
+	}
+}
@@ -1,64 +1,71 @@
 package parser
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/BarneyRubble12/specdrill/internal/core/domain"
-	"github.com/BarneyRubble12/specdrill/internal/core/model"
 	"github.com/getkin/kin-openapi/openapi3"
 )
 
+// Options controls how specs are loaded
+type Options struct {
+	// Timeout limits how long fetching a remote document may take; zero
+	// means no limit
+	Timeout time.Duration
+}
+
 // Parser handles parsing of OpenAPI specifications
 type Parser struct {
 	client *http.Client
 }
 
 // NewParser creates a new Parser instance
-func NewParser() *Parser {
+func NewParser(options Options) *Parser {
 	return &Parser{
-		client: &http.Client{},
+		client: &http.Client{Timeout: options.Timeout},
 	}
 }
 
-// ParseSpec parses an OpenAPI specification from a file or URL
-func (p *Parser) ParseSpec(specPath string, baseURL string) (*domain.APISpec, error) {
+// ParseSpec parses an OpenAPI specification from a file or URL. Fetching
+// remote documents stops when ctx is done.
+func (p *Parser) ParseSpec(ctx context.Context, specPath string, baseURL string) (*domain.APISpec, error) {
 	var specData []byte
+	var contentType string
+	var location *url.URL
 	var err error
 
 	// Check if specPath is a URL
 	if strings.HasPrefix(specPath, "http://") || strings.HasPrefix(specPath, "https://") {
+		location, err = url.Parse(specPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse spec URL: %w", err)
+		}
+
 		// For remote specs, if no base URL is provided, derive it from the spec URL
 		if baseURL == "" {
-			parsedURL, err := url.Parse(specPath)
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse spec URL: %w", err)
-			}
 			// Remove the path and query components to get the base URL
+			parsedURL := *location
 			parsedURL.Path = ""
+			parsedURL.RawPath = ""
 			parsedURL.RawQuery = ""
+			parsedURL.Fragment = ""
 			baseURL = parsedURL.String()
 		}
 
 		// Fetch the spec from URL
-		resp, err := p.client.Get(specPath)
+		specData, contentType, err = p.fetchRemote(ctx, specPath)
 		if err != nil {
-			return nil, fmt.Errorf("failed to fetch spec from URL: %w", err)
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("failed to fetch spec: HTTP %d", resp.StatusCode)
-		}
-
-		specData, err = io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read spec from URL: %w", err)
+			return nil, err
 		}
 	} else {
 		// For local files, if no base URL is provided, return an error
@@ -66,6 +73,11 @@ func (p *Parser) ParseSpec(specPath string, baseURL string) (*domain.APISpec, er
 			return nil, fmt.Errorf("base URL is required for local spec files")
 		}
 
+		location, err = fileURL(specPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read spec file: %w", err)
+		}
+
 		// Read the spec from file
 		specData, err = os.ReadFile(specPath)
 		if err != nil {
@@ -73,51 +85,154 @@ func (p *Parser) ParseSpec(specPath string, baseURL string) (*domain.APISpec, er
 		}
 	}
 
-	// Parse the spec
-	var spec domain.APISpec
-	if err := json.Unmarshal(specData, &spec); err != nil {
+	// Decode the document as JSON or YAML
+	doc, pos, err := decodeDocument(specData, detectFormat(location.Path, contentType, specData))
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse spec: %w", err)
 	}
 
-	// Set the base URL
-	spec.BaseURL = baseURL
+	// Convert Swagger 2.0 documents to OpenAPI 3 before anything else looks
+	// at them. Source positions no longer apply to the converted document.
+	var warnings []string
+	var basePath string
+	if isSwagger2(doc) {
+		basePath, _ = doc.(map[string]interface{})["basePath"].(string)
+		doc, specData, warnings, err = p.convertSwagger2(ctx, doc, location)
+		if err != nil {
+			return nil, err
+		}
+		pos = nil
+	}
 
-	return &spec, nil
+	// Inline every $ref so consumers see a fully dereferenced document
+	doc, err = newResolver(ctx, p, location, doc).resolve(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Map the document onto the domain model
+	spec, err := toDomain(doc, pos)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse spec: %w", err)
+	}
+
+	// Load and validate the spec with kin-openapi so correctness problems
+	// surface before any request is sent. kin-openapi only understands
+	// OpenAPI 3.0, so 3.1 documents rely on our own loading alone.
+	if strings.HasPrefix(spec.OpenAPI, "3.1") {
+		warnings = append(warnings, "OpenAPI 3.1 document: kin-openapi validation was skipped")
+	} else {
+		spec.Document, err = p.loadDocument(ctx, specData, location)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Set the base URL, under the basePath a Swagger 2.0 spec puts every
+	// path beneath
+	spec.BaseURL = withBasePath(baseURL, basePath)
+	spec.Warnings = warnings
+
+	return spec, nil
 }
 
-// createTestCase creates a test case from an operation
-func createTestCase(method, path string, operation *openapi3.Operation) model.TestCase {
-	testCase := model.TestCase{
-		Name:           fmt.Sprintf("%s %s", method, path),
-		Method:         method,
-		Path:           path,
-		ExpectedStatus: getExpectedStatus(operation),
-		Description:    operation.Summary,
+// loadDocument loads the spec through openapi3.Loader and runs its document
+// validation
+func (p *Parser) loadDocument(ctx context.Context, data []byte, location *url.URL) (*openapi3.T, error) {
+	loader := p.newLoader(ctx, location)
+	doc, err := loader.LoadFromDataWithPath(data, location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load spec: %w", err)
 	}
 
-	// Add request body if present
-	if operation.RequestBody != nil {
-		// TODO: Extract example request body from schema
-		testCase.RequestBody = nil
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("invalid spec: %w", err)
 	}
+	return doc, nil
+}
 
-	return testCase
+// newLoader returns an openapi3.Loader that follows external references
+// using the parser's HTTP client. Local files are only read for specs that
+// are local files themselves.
+func (p *Parser) newLoader(ctx context.Context, root *url.URL) *openapi3.Loader {
+	readers := []openapi3.ReadFromURIFunc{
+		func(_ *openapi3.Loader, location *url.URL) ([]byte, error) {
+			if location.Scheme != "http" && location.Scheme != "https" {
+				return nil, openapi3.ErrURINotSupported
+			}
+			data, _, err := p.fetchRemote(ctx, location.String())
+			return data, err
+		},
+	}
+	if root.Scheme == "file" {
+		readers = append(readers, openapi3.ReadFromFile)
+	}
+
+	loader := openapi3.NewLoader()
+	loader.Context = ctx
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = openapi3.URIMapCache(openapi3.ReadFromURIs(readers...))
+	return loader
 }
 
-// getExpectedStatus extracts the expected status code from the operation
-func getExpectedStatus(operation *openapi3.Operation) int {
-	// Default to 200 if no responses are defined
-	if operation.Responses == nil {
-		return 200
+// fetchRemote downloads a document over HTTP and returns it along with its
+// Content-Type
+func (p *Parser) fetchRemote(ctx context.Context, location string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch spec from URL: %w", err)
 	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch spec from URL: %w", err)
+	}
+	defer resp.Body.Close()
 
-	// Look for 2xx responses first
-	for status := range operation.Responses.Map() {
-		if status[0] == '2' {
-			return 200 // Default to 200 for any 2xx response
-		}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to fetch spec: HTTP %d", resp.StatusCode)
 	}
 
-	// Just return 200 as default since we can't determine the actual status
-	return 200
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read spec from URL: %w", err)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+// fileURL converts a local path into an absolute file:// URL so relative
+// references can be resolved against it
+func fileURL(path string) (*url.URL, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	return &url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}, nil
+}
+
+// toDomain maps a decoded document onto domain.APISpec. Type mismatches are
+// reported with the source position of the offending node.
+func toDomain(doc interface{}, pos positions) (*domain.APISpec, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var spec domain.APISpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			jsonType, _, _ := strings.Cut(typeErr.Value, " ")
+			if ptr, ok := locateField(doc, typeErr.Field, jsonType); ok {
+				p := pos[ptr]
+				return nil, &ParseError{
+					Line:    p.Line,
+					Column:  p.Column,
+					Pointer: ptr,
+					Err:     fmt.Errorf("cannot use %s as %s", jsonType, typeErr.Type),
+				}
+			}
+		}
+		return nil, err
+	}
+	return &spec, nil
 }
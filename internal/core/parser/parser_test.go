@@ -1,13 +1,18 @@
 package parser
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/BarneyRubble12/specdrill/internal/core/domain"
+	"github.com/BarneyRubble12/specdrill/internal/core/model"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -100,8 +105,8 @@ func TestParseSpec(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			parser := NewParser()
-			spec, err := parser.ParseSpec(tt.specPath, tt.baseURL)
+			parser := NewParser(Options{})
+			spec, err := parser.ParseSpec(context.Background(), tt.specPath, tt.baseURL)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -133,3 +138,714 @@ func TestParseSpec(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSpecYAML(t *testing.T) {
+	parser := NewParser(Options{})
+	spec, err := parser.ParseSpec(context.Background(), filepath.Join("..", "..", "..", "testdata", "petstore.yaml"), "http://localhost:8080")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "3.0.0", spec.OpenAPI)
+	assert.Equal(t, "Petstore API", spec.Info.Title)
+	assert.Contains(t, spec.Paths, "/pets")
+	assert.Contains(t, spec.Paths, "/pets/{petId}")
+	assert.NotNil(t, spec.Paths["/pets"].Post)
+	assert.Contains(t, spec.Paths["/pets"].Post.Responses, "201")
+	assert.Equal(t, "limit", spec.Paths["/pets"].Get.Parameters[0].Name)
+	assert.Equal(t, "integer", spec.Paths["/pets"].Get.Parameters[0].Schema.Type)
+
+	pet := spec.Paths["/pets"].Post.RequestBody.Content["application/json"].Schema
+	assert.Equal(t, "#/components/schemas/Pet", pet.Ref)
+	assert.Equal(t, []string{"id", "name"}, pet.Required)
+
+	// The kin-openapi document is exposed alongside the domain model
+	if assert.NotNil(t, spec.Document) {
+		assert.NotNil(t, spec.Document.Paths.Find("/pets/{petId}"))
+	}
+}
+
+func TestParseSpecValidation(t *testing.T) {
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content string
+		errMsg  string
+	}{
+		{
+			name: "Missing info version",
+			content: `openapi: 3.0.0
+info:
+  title: Test API
+paths: {}
+`,
+			errMsg: "invalid spec",
+		},
+		{
+			name: "Undeclared path parameter",
+			content: `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+paths:
+  /pets/{petId}:
+    get:
+      responses:
+        '200':
+          description: OK
+`,
+			errMsg: "invalid spec",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			specPath := filepath.Join(tempDir, "spec.yaml")
+			if err := os.WriteFile(specPath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test spec: %v", err)
+			}
+
+			_, err := NewParser(Options{}).ParseSpec(context.Background(), specPath, "http://localhost:8080")
+
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		location    string
+		contentType string
+		data        string
+		want        format
+	}{
+		{name: "JSON extension", location: "spec.json", data: "openapi: 3.0.0", want: formatJSON},
+		{name: "YAML extension", location: "spec.yaml", data: `{"openapi": "3.0.0"}`, want: formatYAML},
+		{name: "YML extension", location: "spec.YML", want: formatYAML},
+		{name: "JSON content type", location: "/openapi", contentType: "application/json; charset=utf-8", want: formatJSON},
+		{name: "Vendor JSON content type", location: "/openapi", contentType: "application/vnd.oai.openapi+json", want: formatJSON},
+		{name: "YAML content type", location: "/openapi", contentType: "application/x-yaml", want: formatYAML},
+		{name: "Sniffed JSON", location: "/openapi", data: "  \n{\"openapi\": \"3.0.0\"}", want: formatJSON},
+		{name: "Sniffed YAML", location: "/openapi", contentType: "text/plain", data: "openapi: 3.0.0", want: formatYAML},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, detectFormat(tt.location, tt.contentType, []byte(tt.data)))
+		})
+	}
+}
+
+func TestParseSpecErrorPositions(t *testing.T) {
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		line    int
+		column  int
+		message string
+	}{
+		{
+			name: "YAML type mismatch",
+			file: "spec.yaml",
+			content: `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+paths:
+  /test:
+    get:
+      tags: oops
+      responses:
+        200:
+          description: OK
+`,
+			line:   8,
+			column: 13,
+		},
+		{
+			name:    "YAML syntax error",
+			file:    "spec.yaml",
+			content: "openapi: 3.0.0\ninfo:\n  title: Test: API\n",
+			line:    3,
+			message: "line 3: mapping values are not allowed in this context",
+		},
+		{
+			name:    "JSON syntax error",
+			file:    "spec.json",
+			content: "{\n  \"openapi\": \"3.0.0\",\n  \"info\": {\"title\" \"Test API\"}\n}",
+			line:    3,
+			column:  21,
+		},
+		{
+			name:    "JSON type mismatch",
+			file:    "spec.json",
+			content: "{\n  \"openapi\": \"3.0.0\",\n  \"info\": {\"title\": 42}\n}",
+			line:    3,
+			column:  21,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			specPath := filepath.Join(tempDir, tt.file)
+			if err := os.WriteFile(specPath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test spec: %v", err)
+			}
+
+			_, err := NewParser(Options{}).ParseSpec(context.Background(), specPath, "http://localhost:8080")
+
+			var parseErr *ParseError
+			if assert.ErrorAs(t, err, &parseErr) {
+				assert.Equal(t, tt.line, parseErr.Line)
+				assert.Equal(t, tt.column, parseErr.Column)
+				if tt.message != "" {
+					assert.Equal(t, tt.message, parseErr.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestParseSpecRefs(t *testing.T) {
+	tempDir := t.TempDir()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"type": "string", "example": "dog"}`))
+	}))
+	defer server.Close()
+
+	mainSpec := `openapi: 3.0.0
+info:
+  title: Refs API
+  version: 1.0.0
+paths:
+  /pets:
+    post:
+      requestBody:
+        $ref: '#/components/requestBodies/NewPet'
+      responses:
+        '201':
+          description: Created
+          content:
+            application/json:
+              schema:
+                $ref: './schemas/pet.yaml#/Pet'
+  /nodes:
+    get:
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Node'
+components:
+  requestBodies:
+    NewPet:
+      content:
+        application/json:
+          schema:
+            $ref: './schemas/pet.yaml#/Pet'
+  schemas:
+    Node:
+      type: object
+      example:
+        $ref: not-a-reference
+      properties:
+        children:
+          type: array
+          items:
+            $ref: '#/components/schemas/Node'
+`
+	petSchema := `Pet:
+  type: object
+  properties:
+    name:
+      type: string
+    owner:
+      $ref: '#/Owner'
+    friends:
+      type: array
+      items:
+        $ref: '#/Pet'
+    tag:
+      $ref: '` + server.URL + `/tag.json'
+Owner:
+  type: object
+  properties:
+    id:
+      type: integer
+`
+	if err := os.MkdirAll(filepath.Join(tempDir, "schemas"), 0755); err != nil {
+		t.Fatalf("Failed to create schema dir: %v", err)
+	}
+	specPath := filepath.Join(tempDir, "main.yaml")
+	if err := os.WriteFile(specPath, []byte(mainSpec), 0644); err != nil {
+		t.Fatalf("Failed to write test spec: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "schemas", "pet.yaml"), []byte(petSchema), 0644); err != nil {
+		t.Fatalf("Failed to write test schema: %v", err)
+	}
+
+	spec, err := NewParser(Options{}).ParseSpec(context.Background(), specPath, "http://localhost:8080")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Internal ref to a request body whose schema lives in another file
+	body := spec.Paths["/pets"].Post.RequestBody
+	if assert.NotNil(t, body) {
+		pet := body.Content["application/json"].Schema
+		assert.Equal(t, "./schemas/pet.yaml#/Pet", pet.Ref)
+		assert.Equal(t, "string", pet.Properties["name"].Type)
+		// Fragment-only ref inside the external file resolves against that file
+		assert.Equal(t, "integer", pet.Properties["owner"].Properties["id"].Type)
+		// Recursion inside the external file names the schema it repeats
+		if friends := pet.Properties["friends"].Items; assert.NotNil(t, friends) {
+			assert.True(t, friends.IsRecursive())
+			assert.Equal(t, pet.Ref, friends.Ref)
+		}
+		// Remote URL ref
+		assert.Equal(t, "string", pet.Properties["tag"].Type)
+		assert.Equal(t, "dog", pet.Properties["tag"].Example)
+	}
+
+	// Recursive schemas stop at the point where they refer back to themselves
+	node := spec.Paths["/nodes"].Get.Responses["200"].Content["application/json"].Schema
+	assert.Equal(t, "object", node.Type)
+	children := node.Properties["children"]
+	assert.Equal(t, "array", children.Type)
+	if assert.NotNil(t, children.Items) {
+		assert.Equal(t, "#/components/schemas/Node", children.Items.Ref)
+		assert.Empty(t, children.Items.Type)
+	}
+	// Example data is never treated as a reference
+	assert.Equal(t, map[string]interface{}{"$ref": "not-a-reference"}, node.Example)
+}
+
+func TestParseSpecRefErrors(t *testing.T) {
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content string
+		errMsg  string
+	}{
+		{
+			name: "Circular reference chain",
+			content: `openapi: 3.0.0
+info: {title: Test API, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    A:
+      $ref: '#/components/schemas/B'
+    B:
+      $ref: '#/components/schemas/A'
+`,
+			errMsg: "circular $ref",
+		},
+		{
+			name: "Missing target",
+			content: `openapi: 3.0.0
+info: {title: Test API, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    A:
+      $ref: '#/components/schemas/Missing'
+`,
+			errMsg: `failed to resolve $ref "#/components/schemas/Missing"`,
+		},
+		{
+			name: "Missing file",
+			content: `openapi: 3.0.0
+info: {title: Test API, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    A:
+      $ref: './missing.yaml#/A'
+`,
+			errMsg: "failed to read spec file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			specPath := filepath.Join(tempDir, "spec.yaml")
+			if err := os.WriteFile(specPath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test spec: %v", err)
+			}
+
+			_, err := NewParser(Options{}).ParseSpec(context.Background(), specPath, "http://localhost:8080")
+
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestParseSpecSwagger2(t *testing.T) {
+	spec, err := NewParser(Options{}).ParseSpec(context.Background(), filepath.Join("..", "..", "..", "testdata", "petstore-v2.yaml"), "http://localhost:8080")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "Petstore API", spec.Info.Title)
+	if assert.Len(t, spec.Servers, 1) {
+		assert.Equal(t, "https://petstore.swagger.io/v1", spec.Servers[0].URL)
+	}
+
+	// Body parameters become request bodies and definitions become schemas
+	create := spec.Paths["/pets"].Post
+	if assert.NotNil(t, create.RequestBody) {
+		pet := create.RequestBody.Content["application/json"].Schema
+		assert.Equal(t, "#/components/schemas/Pet", pet.Ref)
+		assert.Equal(t, "string", pet.Properties["name"].Type)
+	}
+
+	// Global produces applies to every response, with examples carried over
+	list := spec.Paths["/pets"].Get
+	listContent := list.Responses["200"].Content
+	assert.Contains(t, listContent, "application/json")
+	assert.Equal(t, "integer", list.Parameters[0].Schema.Type)
+
+	listOp := spec.Document.Paths.Find("/pets").Get
+	assert.NotNil(t, listOp.Responses.Value("200").Value.Content.Get("application/json").Example)
+	tags := listOp.Parameters.GetByInAndName("query", "tags")
+	assert.Equal(t, "pipeDelimited", tags.Style)
+	assert.False(t, *tags.Explode)
+
+	// formData parameters become a form body with per-field encoding
+	upload := spec.Paths["/pets/{petId}/photo"].Post
+	if assert.NotNil(t, upload.RequestBody) {
+		form := upload.RequestBody.Content["application/x-www-form-urlencoded"].Schema
+		assert.Equal(t, []string{"caption"}, form.Required)
+		assert.Equal(t, "array", form.Properties["labels"].Type)
+	}
+	uploadOp := spec.Document.Paths.Find("/pets/{petId}/photo").Post
+	labels := uploadOp.RequestBody.Value.Content.Get("application/x-www-form-urlencoded").Encoding["labels"]
+	assert.True(t, *labels.Explode)
+
+	// Constructs without an OpenAPI 3 equivalent are reported
+	assert.Len(t, spec.Warnings, 2)
+	assert.Contains(t, spec.Warnings[0], "collectionFormat tsv")
+	assert.Contains(t, spec.Warnings[1], "application/xml")
+}
+
+func TestParseSpecOpenAPI31(t *testing.T) {
+	spec, err := NewParser(Options{}).ParseSpec(context.Background(), filepath.Join("..", "..", "..", "testdata", "modern-3.1.yaml"), "http://localhost:8080")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "3.1.0", spec.OpenAPI)
+	assert.Contains(t, spec.Warnings, "OpenAPI 3.1 document: kin-openapi validation was skipped")
+
+	thing := spec.Paths["/things"].Post.RequestBody.Content["application/json"].Schema
+	// Keywords next to a $ref apply on top of the target in 3.1
+	assert.Equal(t, "#/components/schemas/Thing", thing.Ref)
+	assert.Equal(t, "object", thing.Type)
+	if assert.NotNil(t, thing.UnevaluatedProperties) {
+		assert.True(t, thing.UnevaluatedProperties.False)
+	}
+
+	name := thing.Properties["name"]
+	assert.Equal(t, "string", name.Type)
+	assert.Equal(t, []string{"string", "null"}, name.Types)
+	assert.True(t, name.AllowsNull())
+	assert.Equal(t, []interface{}{"widget", nil}, name.Examples)
+
+	kind := thing.Properties["kind"]
+	assert.True(t, kind.HasConst)
+	assert.Equal(t, "thing", kind.Const)
+
+	point := thing.Properties["point"]
+	assert.Len(t, point.PrefixItems, 2)
+	if assert.NotNil(t, point.Items) {
+		assert.True(t, point.Items.False)
+	}
+
+	assert.Equal(t, "integer", thing.Properties["owner"].Properties["id"].Type)
+	assert.Contains(t, thing.Defs, "Owner")
+
+	if assert.Contains(t, spec.Webhooks, "thingCreated") {
+		assert.NotNil(t, spec.Webhooks["thingCreated"].Post)
+	}
+}
+
+func TestParseSpecSecurity(t *testing.T) {
+	spec, err := NewParser(Options{}).ParseSpec(context.Background(), filepath.Join("..", "..", "..", "testdata", "secured.yaml"), "http://localhost:8080")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "header", spec.Components.SecuritySchemes["apiKey"].In)
+	if assert.NotNil(t, spec.Components.SecuritySchemes["petstoreAuth"].Flows) {
+		assert.Equal(t, "/oauth/token", spec.Components.SecuritySchemes["petstoreAuth"].Flows.ClientCredentials.TokenURL)
+	}
+
+	// Operations without security inherit the global requirement, while an
+	// empty list opts out of it
+	assert.Equal(t, []domain.SecurityRequirement{{"apiKey": {}}}, spec.SecurityFor(spec.Paths["/pets"].Get))
+	assert.Empty(t, spec.SecurityFor(spec.Paths["/health"].Get))
+	assert.Equal(t, []domain.SecurityRequirement{
+		{"petstoreAuth": {"pets:write"}},
+		{"basicAuth": {}},
+	}, spec.SecurityFor(spec.Paths["/pets"].Post))
+}
+
+func TestParseSpecComposedSchemas(t *testing.T) {
+	content := `openapi: 3.0.0
+info:
+  title: Composed API
+  version: 1.0.0
+paths:
+  /pets:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              oneOf:
+                - $ref: '#/components/schemas/Cat'
+                - $ref: '#/components/schemas/Dog'
+              discriminator:
+                propertyName: petType
+                mapping:
+                  dog: '#/components/schemas/Dog'
+      responses:
+        '201':
+          description: Created
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [petType]
+      properties:
+        petType:
+          type: string
+    Cat:
+      allOf:
+        - $ref: '#/components/schemas/Pet'
+        - type: object
+          properties:
+            indoor:
+              type: boolean
+    Dog:
+      allOf:
+        - $ref: '#/components/schemas/Pet'
+        - type: object
+          properties:
+            name:
+              type: string
+              not:
+                enum: [Cat]
+`
+	specPath := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(specPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test spec: %v", err)
+	}
+
+	spec, err := NewParser(Options{}).ParseSpec(context.Background(), specPath, "http://localhost:8080")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	schema := spec.Paths["/pets"].Post.RequestBody.Content["application/json"].Schema
+	if assert.Len(t, schema.OneOf, 2) && assert.NotNil(t, schema.Discriminator) {
+		assert.Equal(t, "petType", schema.Discriminator.PropertyName)
+
+		dog, ok := schema.Discriminate(map[string]interface{}{"petType": "dog"})
+		assert.True(t, ok)
+		merged := dog.MergeAllOf()
+		assert.Equal(t, []string{"petType"}, merged.Required)
+		assert.Contains(t, merged.Properties, "name")
+		assert.NotNil(t, merged.Properties["name"].Not)
+	}
+}
+
+func TestParseTestCases(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"a-single.json": `{
+  "name": "POST /pets: minimal",
+  "method": "POST",
+  "path": "/pets",
+  "content_type": "application/json",
+  "request_body": {"id": 9223372036854775808},
+  "expected_statuses": ["201"]
+}`,
+		"b-list.json": `[
+  {"method": "get", "path": "/pets", "params": [{"name": "limit", "in": "query", "value": 10}]},
+  {"name": "DELETE /pets/{petId}", "method": "DELETE", "path": "/pets/{petId}", "expected_statuses": ["204", "404"], "negative": true}
+]`,
+		"notes.txt": "not a test case",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test cases: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		location  string
+		content   string
+		wantNames []string
+		errMsg    string
+	}{
+		{
+			name:      "Directory",
+			location:  tempDir,
+			wantNames: []string{"POST /pets: minimal", "GET /pets", "DELETE /pets/{petId}"},
+		},
+		{
+			name:      "Single file",
+			location:  filepath.Join(tempDir, "a-single.json"),
+			wantNames: []string{"POST /pets: minimal"},
+		},
+		{
+			name:     "Missing file",
+			location: filepath.Join(tempDir, "missing.json"),
+			errMsg:   "failed to read test cases",
+		},
+		{
+			name:     "Case without a method",
+			location: filepath.Join(t.TempDir(), "broken.json"),
+			content:  `{"path": "/pets"}`,
+			errMsg:   "has no method or path",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.content != "" {
+				if err := os.WriteFile(tt.location, []byte(tt.content), 0644); err != nil {
+					t.Fatalf("Failed to write test cases: %v", err)
+				}
+			}
+
+			cases, err := NewParser(Options{}).ParseTestCases(tt.location)
+			if tt.errMsg != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				return
+			}
+			assert.NoError(t, err)
+
+			var names []string
+			for _, testCase := range cases {
+				names = append(names, testCase.Name)
+			}
+			assert.Equal(t, tt.wantNames, names)
+		})
+	}
+
+	cases, err := NewParser(Options{}).ParseTestCases(tempDir)
+	assert.NoError(t, err)
+	// Numbers keep every digit, and the method is normalised; statuses are
+	// left for the executor to fill in from the spec
+	assert.Equal(t, map[string]interface{}{"id": json.Number("9223372036854775808")}, cases[0].RequestBody)
+	assert.Equal(t, "GET", cases[1].Method)
+	assert.Empty(t, cases[1].ExpectedStatuses)
+	assert.Equal(t, []model.Param{{Name: "limit", In: "query", Value: json.Number("10")}}, cases[1].Params)
+	assert.True(t, cases[2].Negative)
+}
+
+func TestParseSpecCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("openapi: 3.0.0\n"))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewParser(Options{}).ParseSpec(ctx, server.URL+"/openapi.yaml", "")
+
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestParseSpecTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	start := time.Now()
+	_, err := NewParser(Options{Timeout: 50 * time.Millisecond}).ParseSpec(context.Background(), server.URL+"/openapi.yaml", "")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to fetch spec from URL")
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestParseSpecRemoteFileRefs(t *testing.T) {
+	secretPath := filepath.Join(t.TempDir(), "secret.yaml")
+	if err := os.WriteFile(secretPath, []byte("Secret:\n  type: string\n  example: TOPSECRET\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test schema: %v", err)
+	}
+	ref := "file://" + filepath.ToSlash(secretPath) + "#/Secret"
+
+	specs := map[string]string{
+		"/openapi.yaml": `openapi: 3.0.0
+info: {title: Test API, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    A:
+      $ref: '` + ref + `'
+`,
+		"/swagger.yaml": `swagger: "2.0"
+info: {title: Test API, version: 1.0.0}
+paths: {}
+definitions:
+  A:
+    $ref: '` + ref + `'
+`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(specs[r.URL.Path]))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		location string
+		errMsg   string
+	}{
+		{name: "Remote OpenAPI 3 spec", location: server.URL + "/openapi.yaml", errMsg: "cannot be referenced from a remote spec"},
+		{name: "Remote Swagger 2.0 spec", location: server.URL + "/swagger.yaml", errMsg: "unsupported URI"},
+		{name: "Local spec", location: filepath.Join(t.TempDir(), "openapi.yaml")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.HasPrefix(tt.location, "http") {
+				if err := os.WriteFile(tt.location, []byte(specs["/openapi.yaml"]), 0644); err != nil {
+					t.Fatalf("Failed to write test spec: %v", err)
+				}
+			}
+
+			spec, err := NewParser(Options{}).ParseSpec(context.Background(), tt.location, "http://localhost:8080")
+			if tt.errMsg != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				assert.Nil(t, spec)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, "TOPSECRET", spec.Components.Schemas["A"].Example)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// literalKeys hold example data rather than spec objects, so a "$ref" key
+// inside them is plain data and must not be followed
+var literalKeys = map[string]bool{
+	"example": true,
+	"default": true,
+	"enum":    true,
+	"const":   true,
+	"value":   true,
+}
+
+// namedKeys hold maps keyed by user-chosen names (property names, status
+// codes, media types...). Their keys are never spec keywords.
+var namedKeys = map[string]bool{
+	"paths":             true,
+	"webhooks":          true,
+	"schemas":           true,
+	"responses":         true,
+	"parameters":        true,
+	"requestBodies":     true,
+	"headers":           true,
+	"securitySchemes":   true,
+	"links":             true,
+	"callbacks":         true,
+	"pathItems":         true,
+	"properties":        true,
+	"patternProperties": true,
+	"dependentSchemas":  true,
+	"definitions":       true,
+	"$defs":             true,
+	"content":           true,
+	"encoding":          true,
+	"examples":          true,
+	"mapping":           true,
+	"scopes":            true,
+	"variables":         true,
+}
+
+// resolver inlines $ref targets from the same document, relative files and
+// remote URLs. Each inlined object keeps its original "$ref" value so
+// consumers can tell where it came from. A reference back into a schema
+// that is still being expanded is left as a bare {"$ref": ...} object,
+// which marks the point where a recursive structure repeats. Its "$ref" is
+// the one the enclosing expansion was entered through, however the
+// recursive reference was written, so it names an object in the output.
+type resolver struct {
+	ctx    context.Context
+	parser *Parser
+	root   *url.URL
+	docs   map[string]interface{}
+	stack  []expansion
+	// keepSiblings applies keywords next to a $ref on top of its target, as
+	// OpenAPI 3.1 does. In 3.0 they are ignored.
+	keepSiblings bool
+}
+
+// expansion is a reference target being resolved: its absolute location and
+// the reference it was entered through
+type expansion struct {
+	key string
+	ref string
+}
+
+func newResolver(ctx context.Context, p *Parser, location *url.URL, doc interface{}) *resolver {
+	root := *location
+	root.Fragment = ""
+	version, _ := asMap(doc)["openapi"].(string)
+	return &resolver{
+		ctx:          ctx,
+		parser:       p,
+		root:         &root,
+		docs:         map[string]interface{}{root.String(): doc},
+		keepSiblings: strings.HasPrefix(version, "3.1"),
+	}
+}
+
+// resolve returns a copy of doc with every reference replaced by its target
+func (r *resolver) resolve(doc interface{}) (interface{}, error) {
+	return r.node(doc, r.root, false, false)
+}
+
+// node resolves a single value. named is set for maps whose keys are
+// user-chosen names, direct when the value is itself the target of a $ref.
+func (r *resolver) node(v interface{}, base *url.URL, named, direct bool) (interface{}, error) {
+	switch n := v.(type) {
+	case map[string]interface{}:
+		if ref, ok := n["$ref"].(string); ok && !named {
+			resolved, err := r.ref(ref, base, direct)
+			if err != nil || !r.keepSiblings || len(n) == 1 {
+				return resolved, err
+			}
+			return r.siblings(n, resolved, base)
+		}
+
+		out := make(map[string]interface{}, len(n))
+		for k, child := range n {
+			_, isArray := child.([]interface{})
+			if !named && (literalKeys[k] || (k == "examples" && isArray)) {
+				out[k] = child
+				continue
+			}
+			resolved, err := r.node(child, base, !named && namedKeys[k], false)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = resolved
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(n))
+		for i, child := range n {
+			resolved, err := r.node(child, base, false, false)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+// ref resolves a single reference relative to base
+func (r *resolver) ref(ref string, base *url.URL, direct bool) (interface{}, error) {
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid $ref %q: %w", ref, err)
+	}
+	target := base.ResolveReference(refURL)
+	key := target.String()
+
+	for _, active := range r.stack {
+		if active.key != key {
+			continue
+		}
+		// A reference whose target is another reference back to itself
+		// never reaches an actual object
+		if direct {
+			return nil, fmt.Errorf("circular $ref %q", ref)
+		}
+		return map[string]interface{}{"$ref": active.ref}, nil
+	}
+
+	docURL := *target
+	docURL.Fragment = ""
+	doc, err := r.document(&docURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve $ref %q: %w", ref, err)
+	}
+
+	value, err := lookupPointer(doc, target.Fragment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve $ref %q: %w", ref, err)
+	}
+
+	r.stack = append(r.stack, expansion{key: key, ref: ref})
+	resolved, err := r.node(value, &docURL, false, true)
+	r.stack = r.stack[:len(r.stack)-1]
+	if err != nil {
+		return nil, err
+	}
+
+	obj, ok := resolved.(map[string]interface{})
+	if !ok {
+		return resolved, nil
+	}
+	out := make(map[string]interface{}, len(obj)+1)
+	for k, v := range obj {
+		out[k] = v
+	}
+	out["$ref"] = ref
+	return out, nil
+}
+
+// siblings overlays the keywords found next to a $ref onto its resolved target
+func (r *resolver) siblings(n map[string]interface{}, resolved interface{}, base *url.URL) (interface{}, error) {
+	target, ok := resolved.(map[string]interface{})
+	if !ok {
+		return resolved, nil
+	}
+
+	rest := make(map[string]interface{}, len(n)-1)
+	for k, v := range n {
+		if k != "$ref" {
+			rest[k] = v
+		}
+	}
+	extra, err := r.node(rest, base, false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]interface{}, len(target)+len(rest))
+	for k, v := range target {
+		out[k] = v
+	}
+	for k, v := range extra.(map[string]interface{}) {
+		out[k] = v
+	}
+	return out, nil
+}
+
+// document returns the decoded document at location, loading it on first use
+func (r *resolver) document(location *url.URL) (interface{}, error) {
+	key := location.String()
+	if doc, ok := r.docs[key]; ok {
+		return doc, nil
+	}
+
+	var data []byte
+	var contentType string
+	var err error
+	switch location.Scheme {
+	case "http", "https":
+		data, contentType, err = r.parser.fetchRemote(r.ctx, key)
+	case "file":
+		// A remote spec must not pull local files into the requests sent
+		if r.root.Scheme != "file" {
+			err = fmt.Errorf("local file %s cannot be referenced from a remote spec", location.Path)
+			break
+		}
+		data, err = os.ReadFile(filepath.FromSlash(location.Path))
+		if err != nil {
+			err = fmt.Errorf("failed to read spec file: %w", err)
+		}
+	default:
+		err = fmt.Errorf("unsupported reference scheme %q", location.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	doc, _, err := decodeDocument(data, detectFormat(location.Path, contentType, data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", key, err)
+	}
+	r.docs[key] = doc
+	return doc, nil
+}
+
+// lookupPointer evaluates a JSON pointer against a decoded document
+func lookupPointer(doc interface{}, pointer string) (interface{}, error) {
+	if pointer == "" {
+		return doc, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+
+	current := doc
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = unescapePointer(token)
+		switch n := current.(type) {
+		case map[string]interface{}:
+			next, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("%q not found", pointer)
+			}
+			current = next
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil, fmt.Errorf("%q not found", pointer)
+			}
+			current = n[i]
+		default:
+			return nil, fmt.Errorf("%q not found", pointer)
+		}
+	}
+	return current, nil
+}
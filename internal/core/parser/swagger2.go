@@ -0,0 +1,271 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+)
+
+// swagger2Methods are the operation keys of a Swagger 2.0 path item
+var swagger2Methods = []string{"get", "put", "post", "delete", "options", "head", "patch"}
+
+// isSwagger2 reports whether a decoded document is a Swagger 2.0 spec
+func isSwagger2(doc interface{}) bool {
+	root, ok := doc.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	return fmt.Sprint(root["swagger"]) == "2.0"
+}
+
+// withBasePath appends a Swagger 2.0 basePath to the base URL, unless the
+// base URL already ends with it
+func withBasePath(baseURL, basePath string) string {
+	basePath = strings.Trim(basePath, "/")
+	trimmed := strings.TrimRight(baseURL, "/")
+	if basePath == "" || strings.HasSuffix(trimmed, "/"+basePath) {
+		return baseURL
+	}
+	return trimmed + "/" + basePath
+}
+
+// convertSwagger2 converts a decoded Swagger 2.0 document into the OpenAPI 3
+// equivalent. It returns the converted tree, its serialised form for
+// kin-openapi, and notes on constructs that could not be carried over
+// faithfully.
+func (p *Parser) convertSwagger2(ctx context.Context, doc interface{}, location *url.URL) (interface{}, []byte, []string, error) {
+	src := doc.(map[string]interface{})
+	c := &swagger2Converter{}
+	c.applyDefaultMediaTypes(src)
+
+	data, err := json.Marshal(src)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var doc2 openapi2.T
+	if err := json.Unmarshal(data, &doc2); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to parse Swagger 2.0 spec: %w", err)
+	}
+
+	doc3, err := openapi2conv.ToV3WithLoader(&doc2, p.newLoader(ctx, location), location)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to convert Swagger 2.0 spec: %w", err)
+	}
+	data, err = json.Marshal(doc3)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	converted, _, err := decodeJSON(data)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Fill in what openapi2conv leaves out
+	dst := converted.(map[string]interface{})
+	c.applyCollectionFormats(src, dst)
+	c.applyResponseExamples(src, dst)
+
+	data, err = json.Marshal(dst)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	sort.Strings(c.warnings)
+	return dst, data, c.warnings, nil
+}
+
+// swagger2Converter collects warnings while patching a converted document
+type swagger2Converter struct {
+	warnings []string
+}
+
+func (c *swagger2Converter) warn(format string, args ...interface{}) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+// applyDefaultMediaTypes copies the global produces/consumes onto each
+// operation, since openapi2conv only looks at operation-level produces.
+// Bodies without any declared media type default to JSON, or to a form
+// encoding when they come from formData parameters.
+func (c *swagger2Converter) applyDefaultMediaTypes(src map[string]interface{}) {
+	for _, item := range asMap(src["paths"]) {
+		item := asMap(item)
+		for _, method := range swagger2Methods {
+			op := asMap(item[method])
+			if op == nil {
+				continue
+			}
+			if _, ok := op["produces"]; !ok && src["produces"] != nil {
+				op["produces"] = src["produces"]
+			}
+			if _, ok := op["consumes"]; ok {
+				continue
+			}
+			if src["consumes"] != nil {
+				op["consumes"] = src["consumes"]
+				continue
+			}
+			for _, param := range append(asSlice(item["parameters"]), asSlice(op["parameters"])...) {
+				switch asMap(param)["in"] {
+				case "formData":
+					op["consumes"] = []interface{}{"application/x-www-form-urlencoded"}
+				case "body":
+					op["consumes"] = []interface{}{"application/json"}
+				}
+			}
+		}
+	}
+}
+
+// applyCollectionFormats translates collectionFormat, which openapi2conv
+// drops, into the equivalent OpenAPI 3 style and explode settings
+func (c *swagger2Converter) applyCollectionFormats(src, dst map[string]interface{}) {
+	components := asMap(dst["components"])
+	for name, param := range asMap(src["parameters"]) {
+		c.applyCollectionFormat("parameter "+name, asMap(param), asMap(asMap(components["parameters"])[name]), nil)
+	}
+
+	dstPaths := asMap(dst["paths"])
+	for path, item := range asMap(src["paths"]) {
+		item := asMap(item)
+		dstItem := asMap(dstPaths[path])
+		for _, method := range swagger2Methods {
+			op := asMap(item[method])
+			if op == nil {
+				continue
+			}
+			dstOp := asMap(dstItem[method])
+			where := fmt.Sprintf("%s %s", method, path)
+			for _, param := range append(asSlice(item["parameters"]), asSlice(op["parameters"])...) {
+				param := asMap(param)
+				c.applyCollectionFormat(where, param, findParameter(dstOp, dstItem, param), dstOp)
+			}
+		}
+	}
+}
+
+func (c *swagger2Converter) applyCollectionFormat(where string, param, dstParam, dstOp map[string]interface{}) {
+	if param == nil || param["type"] != "array" {
+		return
+	}
+	name, in := fmt.Sprint(param["name"]), fmt.Sprint(param["in"])
+	collectionFormat, _ := param["collectionFormat"].(string)
+	if collectionFormat == "" {
+		collectionFormat = "csv"
+	}
+
+	var style string
+	explode := false
+	switch collectionFormat {
+	case "csv":
+		style = "form"
+		if in == "path" || in == "header" {
+			style = "simple"
+		}
+	case "ssv":
+		style = "spaceDelimited"
+	case "pipes":
+		style = "pipeDelimited"
+	case "multi":
+		style, explode = "form", true
+	case "tsv":
+		style = "form"
+		if in == "path" || in == "header" {
+			style = "simple"
+		}
+		c.warn("%s: parameter %q uses collectionFormat tsv, which has no OpenAPI 3 equivalent; it is sent comma separated", where, name)
+	default:
+		c.warn("%s: parameter %q has unknown collectionFormat %q", where, name, collectionFormat)
+		return
+	}
+
+	if (style == "spaceDelimited" || style == "pipeDelimited") && in != "query" && in != "formData" {
+		c.warn("%s: parameter %q uses collectionFormat %s outside a query string; it is sent comma separated", where, name, collectionFormat)
+		style = "simple"
+	}
+	if collectionFormat == "multi" && in != "query" && in != "formData" {
+		c.warn("%s: parameter %q uses collectionFormat multi, which is only valid for query and formData parameters", where, name)
+		style, explode = "simple", false
+	}
+
+	if in == "formData" {
+		// Form fields become properties of the request body, encoded per
+		// field. Multipart bodies have no styles, so arrays repeat the field.
+		for mediaType, content := range asMap(asMap(asMap(dstOp["requestBody"]))["content"]) {
+			if mediaType != "application/x-www-form-urlencoded" {
+				if collectionFormat != "multi" {
+					c.warn("%s: parameter %q uses collectionFormat %s in a %s body; it is sent as repeated fields", where, name, collectionFormat, mediaType)
+				}
+				continue
+			}
+			content := asMap(content)
+			encoding := asMap(content["encoding"])
+			if encoding == nil {
+				encoding = map[string]interface{}{}
+				content["encoding"] = encoding
+			}
+			encoding[name] = map[string]interface{}{"style": style, "explode": explode}
+		}
+		return
+	}
+
+	if dstParam != nil {
+		dstParam["style"] = style
+		dstParam["explode"] = explode
+	}
+}
+
+// applyResponseExamples carries Swagger 2.0 response examples, keyed by
+// media type, over to the matching OpenAPI 3 media type objects
+func (c *swagger2Converter) applyResponseExamples(src, dst map[string]interface{}) {
+	dstPaths := asMap(dst["paths"])
+	for path, item := range asMap(src["paths"]) {
+		for _, method := range swagger2Methods {
+			op := asMap(asMap(item)[method])
+			if op == nil {
+				continue
+			}
+			dstResponses := asMap(asMap(asMap(dstPaths[path])[method])["responses"])
+			for code, response := range asMap(op["responses"]) {
+				content := asMap(asMap(dstResponses[code])["content"])
+				for mediaType, example := range asMap(asMap(response)["examples"]) {
+					mt := asMap(content[mediaType])
+					if mt == nil {
+						c.warn("%s %s: response %s example for %s dropped because the operation does not produce that media type", method, path, code, mediaType)
+						continue
+					}
+					mt["example"] = example
+				}
+			}
+		}
+	}
+}
+
+// findParameter locates the converted counterpart of a Swagger 2.0
+// parameter, looking at the operation first and then the path item
+func findParameter(dstOp, dstItem, param map[string]interface{}) map[string]interface{} {
+	for _, holder := range []map[string]interface{}{dstOp, dstItem} {
+		for _, candidate := range asSlice(holder["parameters"]) {
+			candidate := asMap(candidate)
+			if candidate["name"] == param["name"] && candidate["in"] == param["in"] {
+				return candidate
+			}
+		}
+	}
+	return nil
+}
+
+func asMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func asSlice(v interface{}) []interface{} {
+	s, _ := v.([]interface{})
+	return s
+}
@@ -0,0 +1,110 @@
+package validator
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/BarneyRubble12/specdrill/internal/core/domain"
+	"github.com/BarneyRubble12/specdrill/internal/core/model"
+)
+
+// CheckDrift validates a response like ValidateResponse, but reports
+// undocumented behaviour separately as drift instead of failing on it: enum
+// values outside the declared set, and JSON properties a schema does not
+// declare while leaving additionalProperties unspecified.
+func (v *Validator) CheckDrift(spec *domain.APISpec, operation *domain.Operation, method string, code int, status string, header http.Header, body []byte) ([]model.Violation, []model.Violation) {
+	return v.checkResponse(spec, operation, method, code, status, header, body, true)
+}
+
+func (c *check) warn(pointer, keyword, expected, actual string) {
+	c.drift = append(c.drift, model.Violation{
+		Pointer:  pointer,
+		Keyword:  keyword,
+		Expected: expected,
+		Actual:   actual,
+	})
+}
+
+// undeclared walks a value looking for object properties the schema does
+// not mention. The schema is flattened first so properties declared by
+// allOf members or by the matching oneOf/anyOf alternative count.
+func (c *check) undeclared(s domain.Schema, value interface{}, pointer string) {
+	if s.IsRecursive() {
+		target, ok := c.resolve(s.Ref)
+		if !ok {
+			return
+		}
+		c.depth++
+		c.undeclared(target, value, pointer)
+		c.depth--
+		return
+	}
+	if s.Ref != "" {
+		// Copied, as s is flattened below
+		referenced := s
+		c.scope = append(c.scope, &referenced)
+		defer func() { c.scope = c.scope[:len(c.scope)-1] }()
+	}
+	s = c.flatten(s, value, pointer)
+
+	switch v := value.(type) {
+	case []interface{}:
+		for i, item := range v {
+			itemPointer := pointer + "/" + strconv.Itoa(i)
+			switch {
+			case i < len(s.PrefixItems):
+				c.undeclared(s.PrefixItems[i], item, itemPointer)
+			case s.Items != nil:
+				c.undeclared(*s.Items, item, itemPointer)
+			}
+		}
+	case map[string]interface{}:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			propPointer := pointer + "/" + escapePointer(name)
+			prop, declared := s.Properties[name]
+			switch {
+			case declared:
+				c.undeclared(prop, v[name], propPointer)
+			case s.AdditionalProperties != nil:
+				c.undeclared(*s.AdditionalProperties, v[name], propPointer)
+			case s.UnevaluatedProperties != nil:
+				c.undeclared(*s.UnevaluatedProperties, v[name], propPointer)
+			case len(s.Properties) > 0:
+				// A schema without any properties describes a free-form object
+				c.warn(propPointer, "additionalProperties", "declared properties only", "property "+name)
+			}
+		}
+	}
+}
+
+// flatten merges allOf members and the alternative a value matches into a
+// single schema
+func (c *check) flatten(s domain.Schema, value interface{}, pointer string) domain.Schema {
+	s = s.MergeAllOf()
+	alts := s.Alternatives()
+	if len(alts) == 0 {
+		return s
+	}
+
+	alt, ok := s.Discriminate(value)
+	if !ok {
+		for _, candidate := range alts {
+			if c.valid(candidate, value, pointer) {
+				alt, ok = candidate, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return s
+	}
+	s.OneOf, s.AnyOf, s.Discriminator = nil, nil, nil
+	return c.flatten(domain.Schema{AllOf: []domain.Schema{s, alt}}, value, pointer)
+}
@@ -0,0 +1,640 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/BarneyRubble12/specdrill/internal/core/domain"
+	"github.com/BarneyRubble12/specdrill/internal/core/model"
+)
+
+// componentSchemaPrefix is the reference prefix recursive schemas are
+// looked up under when no enclosing schema came from the same reference
+const componentSchemaPrefix = "#/components/schemas/"
+
+// maxDepth guards against recursive references that never consume input
+const maxDepth = 64
+
+// uuidPattern matches the canonical textual form of a UUID
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Validator checks API responses against the schemas a spec documents
+type Validator struct{}
+
+// NewValidator creates a new Validator instance
+func NewValidator() *Validator {
+	return &Validator{}
+}
+
+// ValidateResponse checks a response against what is documented for its
+// status key: the Content-Type must be one of the documented media types,
+// documented headers must be present when required and match their schemas,
+// and JSON bodies must match the media type's schema. method and code are
+// the request method and response status code; responses to HEAD and 204
+// and 304 responses carry no body, so only their headers are checked.
+func (v *Validator) ValidateResponse(spec *domain.APISpec, operation *domain.Operation, method string, code int, status string, header http.Header, body []byte) []model.Violation {
+	violations, _ := v.checkResponse(spec, operation, method, code, status, header, body, false)
+	return violations
+}
+
+// checkResponse validates a response; when lenient, departures that count as
+// drift rather than errors are returned separately
+func (v *Validator) checkResponse(spec *domain.APISpec, operation *domain.Operation, method string, code int, status string, header http.Header, body []byte, lenient bool) ([]model.Violation, []model.Violation) {
+	if operation == nil {
+		return nil, nil
+	}
+	response, ok := operation.Responses[status]
+	if !ok {
+		return nil, nil
+	}
+	c := newCheck(spec, lenient)
+	c.headers(response, header)
+	if !bodiless(method, code) {
+		c.body(response, header.Get("Content-Type"), body)
+	}
+	return c.violations, c.drift
+}
+
+// bodiless reports whether a response never has a body, whatever
+// Content-Type the server sends with it
+func bodiless(method string, code int) bool {
+	return method == http.MethodHead || code == http.StatusNoContent || code == http.StatusNotModified
+}
+
+// headers checks the documented response headers. Content-Type is
+// described by the response content instead, as OpenAPI requires.
+func (c *check) headers(response domain.Response, header http.Header) {
+	names := make([]string, 0, len(response.Headers))
+	for name := range response.Headers {
+		if !strings.EqualFold(name, "Content-Type") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		documented := response.Headers[name]
+		values := header.Values(name)
+		if len(values) == 0 {
+			if documented.Required {
+				c.violations = append(c.violations, model.Violation{
+					Header:   name,
+					Keyword:  "required",
+					Expected: "header " + name,
+					Actual:   "missing",
+				})
+			}
+			continue
+		}
+
+		value := headerValue(documented.Schema, strings.Join(values, ", "))
+		violations, drift := len(c.violations), len(c.drift)
+		c.schema(documented.Schema, value, "")
+		for i := violations; i < len(c.violations); i++ {
+			c.violations[i].Header = name
+		}
+		for i := drift; i < len(c.drift); i++ {
+			c.drift[i].Header = name
+		}
+	}
+}
+
+// body checks the Content-Type of a response against the documented media
+// types and a JSON body against the matching schema
+func (c *check) body(response domain.Response, contentType string, body []byte) {
+	if len(response.Content) == 0 {
+		return
+	}
+	mediaType, ok := MatchMediaType(response.Content, contentType)
+	if !ok {
+		// A response without a body has nothing to describe
+		if contentType == "" && len(body) == 0 {
+			return
+		}
+		documented := make([]string, 0, len(response.Content))
+		for mediaType := range response.Content {
+			documented = append(documented, mediaType)
+		}
+		sort.Strings(documented)
+
+		actual := contentType
+		if actual == "" {
+			actual = "none"
+		}
+		c.violations = append(c.violations, model.Violation{
+			Header:   "Content-Type",
+			Keyword:  "content",
+			Expected: "one of " + strings.Join(documented, ", "),
+			Actual:   actual,
+		})
+		return
+	}
+	schema := response.Content[mediaType].Schema
+	if !IsJSON(mediaType) || reflect.DeepEqual(schema, domain.Schema{}) {
+		return
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		c.fail("", "content", "valid JSON", err.Error())
+		return
+	}
+	c.schema(schema, value, "")
+	if c.lenient {
+		c.undeclared(schema, value, "")
+	}
+}
+
+// Validate checks a decoded JSON value against schema and returns every
+// violation found, ordered by location
+func (v *Validator) Validate(spec *domain.APISpec, schema domain.Schema, value interface{}) []model.Violation {
+	c := newCheck(spec, false)
+	c.schema(schema, value, "")
+	return c.violations
+}
+
+// MatchMediaType finds the documented media type that covers contentType,
+// preferring an exact match over type/* and then */* wildcards
+func MatchMediaType(content map[string]domain.MediaType, contentType string) (string, bool) {
+	actual := contentType
+	if parsed, _, err := mime.ParseMediaType(contentType); err == nil {
+		actual = parsed
+	}
+	actual = strings.ToLower(actual)
+	major, _, _ := strings.Cut(actual, "/")
+
+	candidates := []string{actual, major + "/*", "*/*"}
+	for _, candidate := range candidates {
+		for mediaType := range content {
+			documented := mediaType
+			if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+				documented = parsed
+			}
+			if strings.EqualFold(documented, candidate) {
+				return mediaType, true
+			}
+		}
+	}
+	return "", false
+}
+
+// IsJSON reports whether a media type carries JSON
+func IsJSON(mediaType string) bool {
+	if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+		mediaType = parsed
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// check accumulates the violations found while walking a value
+type check struct {
+	components map[string]domain.Schema
+	violations []model.Violation
+	// lenient records enum mismatches and undeclared properties as drift
+	lenient bool
+	drift   []model.Violation
+	// scope holds the enclosing schemas inlined from a reference, innermost
+	// last, which recursive references point back to
+	scope []*domain.Schema
+	depth int
+}
+
+func newCheck(spec *domain.APISpec, lenient bool) *check {
+	c := &check{lenient: lenient}
+	if spec != nil {
+		c.components = spec.Components.Schemas
+	}
+	return c
+}
+
+func (c *check) fail(pointer, keyword, expected, actual string) {
+	c.violations = append(c.violations, model.Violation{
+		Pointer:  pointer,
+		Keyword:  keyword,
+		Expected: expected,
+		Actual:   actual,
+	})
+}
+
+// valid reports whether value satisfies schema without recording anything
+func (c *check) valid(s domain.Schema, value interface{}, pointer string) bool {
+	sub := &check{components: c.components, scope: c.scope, depth: c.depth}
+	sub.schema(s, value, pointer)
+	return len(sub.violations) == 0
+}
+
+func (c *check) schema(s domain.Schema, value interface{}, pointer string) {
+	if s.False {
+		c.fail(pointer, "false", "no value", describe(value))
+		return
+	}
+	if s.IsRecursive() {
+		target, ok := c.resolve(s.Ref)
+		if !ok {
+			return
+		}
+		c.depth++
+		c.schema(target, value, pointer)
+		c.depth--
+		return
+	}
+	if s.Ref != "" {
+		c.scope = append(c.scope, &s)
+		defer func() { c.scope = c.scope[:len(c.scope)-1] }()
+	}
+
+	if value == nil {
+		if !s.AllowsNull() && s.Type != "" && s.Type != "null" {
+			c.fail(pointer, "type", typeNames(s), "null")
+			return
+		}
+	} else if s.Type != "" && !matchesType(s, value) {
+		c.fail(pointer, "type", typeNames(s), jsonType(value))
+		return
+	}
+
+	if s.HasConst && !reflect.DeepEqual(s.Const, value) {
+		c.fail(pointer, "const", describe(s.Const), describe(value))
+	}
+	if len(s.Enum) > 0 && !containsValue(s.Enum, value) && !(value == nil && s.AllowsNull()) {
+		if c.lenient {
+			c.warn(pointer, "enum", "one of "+describe(s.Enum), describe(value))
+		} else {
+			c.fail(pointer, "enum", "one of "+describe(s.Enum), describe(value))
+		}
+	}
+
+	switch v := value.(type) {
+	case float64:
+		c.number(s, v, pointer)
+	case string:
+		c.string(s, v, pointer)
+	case []interface{}:
+		c.array(s, v, pointer)
+	case map[string]interface{}:
+		c.object(s, v, pointer)
+	}
+
+	c.composition(s, value, pointer)
+}
+
+func (c *check) number(s domain.Schema, v float64, pointer string) {
+	if s.Minimum != nil {
+		if s.ExclusiveMinimum && v <= *s.Minimum {
+			c.fail(pointer, "exclusiveMinimum", "> "+describe(*s.Minimum), describe(v))
+		} else if v < *s.Minimum {
+			c.fail(pointer, "minimum", ">= "+describe(*s.Minimum), describe(v))
+		}
+	}
+	if s.Maximum != nil {
+		if s.ExclusiveMaximum && v >= *s.Maximum {
+			c.fail(pointer, "exclusiveMaximum", "< "+describe(*s.Maximum), describe(v))
+		} else if v > *s.Maximum {
+			c.fail(pointer, "maximum", "<= "+describe(*s.Maximum), describe(v))
+		}
+	}
+	if s.MultipleOf != nil && *s.MultipleOf > 0 {
+		q := v / *s.MultipleOf
+		if math.Abs(q-math.Round(q)) > 1e-9 {
+			c.fail(pointer, "multipleOf", "multiple of "+describe(*s.MultipleOf), describe(v))
+		}
+	}
+}
+
+func (c *check) string(s domain.Schema, v string, pointer string) {
+	length := utf8.RuneCountInString(v)
+	if s.MinLength != nil && length < *s.MinLength {
+		c.fail(pointer, "minLength", fmt.Sprintf("at least %d characters", *s.MinLength), fmt.Sprintf("%d characters", length))
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		c.fail(pointer, "maxLength", fmt.Sprintf("at most %d characters", *s.MaxLength), fmt.Sprintf("%d characters", length))
+	}
+	if s.Pattern != "" {
+		// Patterns RE2 cannot compile are not checked
+		if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(v) {
+			c.fail(pointer, "pattern", "match for "+s.Pattern, describe(v))
+		}
+	}
+	if s.Format != "" && !validFormat(s.Format, v) {
+		c.fail(pointer, "format", s.Format, describe(v))
+	}
+}
+
+func (c *check) array(s domain.Schema, v []interface{}, pointer string) {
+	if s.MinItems != nil && len(v) < *s.MinItems {
+		c.fail(pointer, "minItems", fmt.Sprintf("at least %d items", *s.MinItems), fmt.Sprintf("%d items", len(v)))
+	}
+	if s.MaxItems != nil && len(v) > *s.MaxItems {
+		c.fail(pointer, "maxItems", fmt.Sprintf("at most %d items", *s.MaxItems), fmt.Sprintf("%d items", len(v)))
+	}
+	if s.UniqueItems {
+		for i := range v {
+			for j := i + 1; j < len(v); j++ {
+				if reflect.DeepEqual(v[i], v[j]) {
+					c.fail(fmt.Sprintf("%s/%d", pointer, j), "uniqueItems", "unique items", fmt.Sprintf("duplicate of item %d", i))
+				}
+			}
+		}
+	}
+	for i, item := range v {
+		itemPointer := fmt.Sprintf("%s/%d", pointer, i)
+		switch {
+		case i < len(s.PrefixItems):
+			c.schema(s.PrefixItems[i], item, itemPointer)
+		case s.Items != nil:
+			c.schema(*s.Items, item, itemPointer)
+		}
+	}
+}
+
+func (c *check) object(s domain.Schema, v map[string]interface{}, pointer string) {
+	for _, name := range s.Required {
+		if _, ok := v[name]; !ok {
+			if prop, declared := s.Properties[name]; declared && prop.WriteOnly {
+				continue
+			}
+			c.fail(pointer+"/"+escapePointer(name), "required", "property "+name, "missing")
+		}
+	}
+
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propPointer := pointer + "/" + escapePointer(name)
+		prop, declared := s.Properties[name]
+		switch {
+		case declared && prop.WriteOnly:
+			c.fail(propPointer, "writeOnly", "absent from responses", describe(v[name]))
+		case declared:
+			c.schema(prop, v[name], propPointer)
+		case s.AdditionalProperties != nil && s.AdditionalProperties.False:
+			c.fail(propPointer, "additionalProperties", "no undeclared properties", "property "+name)
+		case s.AdditionalProperties != nil:
+			c.schema(*s.AdditionalProperties, v[name], propPointer)
+		}
+	}
+
+	if s.UnevaluatedProperties == nil {
+		return
+	}
+	evaluated := make(map[string]bool)
+	if c.evaluated(s, v, pointer, evaluated) {
+		return
+	}
+	for _, name := range names {
+		propPointer := pointer + "/" + escapePointer(name)
+		switch {
+		case evaluated[name]:
+		case s.UnevaluatedProperties.False:
+			c.fail(propPointer, "unevaluatedProperties", "no unevaluated properties", "property "+name)
+		default:
+			c.schema(*s.UnevaluatedProperties, v[name], propPointer)
+		}
+	}
+}
+
+// evaluated collects the properties of v that s evaluates: those it
+// declares, and those declared by its allOf members and the alternatives v
+// matches. It reports true when every property is evaluated, through
+// additionalProperties or a member's own unevaluatedProperties.
+func (c *check) evaluated(s domain.Schema, v map[string]interface{}, pointer string, names map[string]bool) bool {
+	for name := range s.Properties {
+		names[name] = true
+	}
+	if s.AdditionalProperties != nil {
+		return true
+	}
+
+	members := append([]domain.Schema(nil), s.AllOf...)
+	if alt, ok := s.Discriminate(v); ok {
+		members = append(members, alt)
+	} else {
+		for _, alt := range s.Alternatives() {
+			if c.valid(alt, v, pointer) {
+				members = append(members, alt)
+			}
+		}
+	}
+	for _, member := range members {
+		if member.IsRecursive() {
+			target, ok := c.resolve(member.Ref)
+			if !ok {
+				// Nothing is known about the member, so assume the best
+				return true
+			}
+			member = target
+		}
+		if member.UnevaluatedProperties != nil || c.evaluated(member, v, pointer, names) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve finds the schema a recursive reference points back to: the
+// innermost enclosing schema inlined from the same reference, else the
+// component schema it names. It reports false when neither is known or the
+// recursion is too deep.
+func (c *check) resolve(ref string) (domain.Schema, bool) {
+	if c.depth > maxDepth {
+		return domain.Schema{}, false
+	}
+	for i := len(c.scope) - 1; i >= 0; i-- {
+		if c.scope[i].Ref == ref {
+			return *c.scope[i], true
+		}
+	}
+	if !strings.HasPrefix(ref, componentSchemaPrefix) {
+		return domain.Schema{}, false
+	}
+	target, ok := c.components[strings.TrimPrefix(ref, componentSchemaPrefix)]
+	return target, ok
+}
+
+// composition checks allOf, oneOf, anyOf and not
+func (c *check) composition(s domain.Schema, value interface{}, pointer string) {
+	for _, member := range s.AllOf {
+		c.schema(member, value, pointer)
+	}
+
+	if alt, ok := s.Discriminate(value); ok {
+		c.schema(alt, value, pointer)
+	} else if len(s.OneOf) > 0 {
+		matches := 0
+		for _, alt := range s.OneOf {
+			if c.valid(alt, value, pointer) {
+				matches++
+			}
+		}
+		if matches != 1 {
+			c.fail(pointer, "oneOf", "exactly one matching schema", fmt.Sprintf("%d matching schemas", matches))
+		}
+	} else if len(s.AnyOf) > 0 {
+		matched := false
+		for _, alt := range s.AnyOf {
+			if c.valid(alt, value, pointer) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			c.fail(pointer, "anyOf", "at least one matching schema", "none")
+		}
+	}
+
+	if s.Not != nil && c.valid(*s.Not, value, pointer) {
+		c.fail(pointer, "not", "value not matching the schema", describe(value))
+	}
+}
+
+// headerValue converts a header's text into the JSON value its schema
+// describes, using the simple style. Text that does not convert is kept as
+// a string so the type check reports it.
+func headerValue(s domain.Schema, raw string) interface{} {
+	raw = strings.TrimSpace(raw)
+	switch s.Type {
+	case "integer", "number":
+		if f, err := strconv.ParseFloat(raw, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if raw == "true" || raw == "false" {
+			return raw == "true"
+		}
+	case "array":
+		var item domain.Schema
+		if s.Items != nil {
+			item = *s.Items
+		}
+		parts := strings.Split(raw, ",")
+		items := make([]interface{}, len(parts))
+		for i, part := range parts {
+			items[i] = headerValue(item, part)
+		}
+		return items
+	case "object":
+		parts := strings.Split(raw, ",")
+		obj := make(map[string]interface{})
+		for i := 0; i < len(parts); i++ {
+			key, value, exploded := strings.Cut(parts[i], "=")
+			if !exploded && i+1 < len(parts) {
+				i++
+				value = parts[i]
+			}
+			key = strings.TrimSpace(key)
+			obj[key] = headerValue(s.Properties[key], value)
+		}
+		return obj
+	}
+	return raw
+}
+
+// matchesType reports whether value has one of the schema's declared types
+func matchesType(s domain.Schema, value interface{}) bool {
+	actual := jsonType(value)
+	if s.AllowsType(actual) {
+		return true
+	}
+	if actual == "number" && s.AllowsType("integer") {
+		return value.(float64) == math.Trunc(value.(float64))
+	}
+	return false
+}
+
+// typeNames lists the types a schema declares
+func typeNames(s domain.Schema) string {
+	if len(s.Types) > 1 {
+		return strings.Join(s.Types, " or ")
+	}
+	return s.Type
+}
+
+// jsonType returns the JSON Schema type name of a decoded JSON value
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// validFormat checks the well-known string formats; unknown formats pass
+func validFormat(format, v string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, v)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", v)
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(v)
+		return err == nil && addr.Address == v
+	case "uuid":
+		return uuidPattern.MatchString(v)
+	case "uri", "url":
+		u, err := url.Parse(v)
+		return err == nil && u.IsAbs()
+	case "ipv4":
+		ip := net.ParseIP(v)
+		return ip != nil && ip.To4() != nil && strings.Contains(v, ".")
+	case "ipv6":
+		ip := net.ParseIP(v)
+		return ip != nil && strings.Contains(v, ":")
+	}
+	return true
+}
+
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, candidate := range values {
+		if reflect.DeepEqual(candidate, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// describe renders a value compactly for violation messages
+func describe(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	const limit = 80
+	if runes := []rune(string(data)); len(runes) > limit {
+		return string(runes[:limit]) + "..."
+	}
+	return string(data)
+}
+
+// escapePointer escapes a property name for use as a JSON pointer token
+func escapePointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
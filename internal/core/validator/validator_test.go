@@ -0,0 +1,413 @@
+package validator
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/BarneyRubble12/specdrill/internal/core/domain"
+	"github.com/BarneyRubble12/specdrill/internal/core/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestValidate(t *testing.T) {
+	pet := domain.Schema{
+		Type:     "object",
+		Required: []string{"id", "name"},
+		Properties: map[string]domain.Schema{
+			"id":       {Type: "integer", Minimum: floatPtr(1)},
+			"name":     {Type: "string", MinLength: intPtr(1)},
+			"status":   {Type: "string", Enum: []interface{}{"available", "sold"}},
+			"tags":     {Type: "array", MaxItems: intPtr(2), UniqueItems: true, Items: &domain.Schema{Type: "string"}},
+			"email":    {Type: "string", Format: "email"},
+			"password": {Type: "string", WriteOnly: true},
+			"parent":   {Ref: "#/components/schemas/Pet"},
+		},
+		AdditionalProperties: &domain.Schema{False: true},
+	}
+	spec := &domain.APISpec{Components: domain.Components{Schemas: map[string]domain.Schema{"Pet": pet}}}
+
+	// A recursive schema from $defs, which the component lookup cannot find
+	treeRef := "#/components/schemas/Forest/$defs/Tree"
+	tree := domain.Schema{
+		Ref:  treeRef,
+		Type: "object",
+		Properties: map[string]domain.Schema{
+			"value":    {Type: "integer"},
+			"children": {Type: "array", Items: &domain.Schema{Ref: treeRef}},
+		},
+	}
+
+	sealed := domain.Schema{
+		Type:                  "object",
+		Properties:            map[string]domain.Schema{"id": {Type: "integer"}},
+		AllOf:                 []domain.Schema{{Properties: map[string]domain.Schema{"name": {Type: "string"}}}},
+		OneOf:                 []domain.Schema{{Required: []string{"dog"}, Properties: map[string]domain.Schema{"dog": {}}}, {Required: []string{"cat"}, Properties: map[string]domain.Schema{"cat": {}}}},
+		UnevaluatedProperties: &domain.Schema{False: true},
+	}
+
+	tests := []struct {
+		name   string
+		schema domain.Schema
+		body   string
+		want   []model.Violation
+	}{
+		{
+			name:   "Valid object",
+			schema: pet,
+			body:   `{"id": 1, "name": "Rex", "status": "sold", "tags": ["a", "b"], "email": "a@example.com"}`,
+		},
+		{
+			name:   "Wrong types",
+			schema: pet,
+			body:   `{"id": 1.5, "name": 7}`,
+			want: []model.Violation{
+				{Pointer: "/id", Keyword: "type", Expected: "integer", Actual: "number"},
+				{Pointer: "/name", Keyword: "type", Expected: "string", Actual: "number"},
+			},
+		},
+		{
+			name:   "Missing, unknown and write-only properties",
+			schema: pet,
+			body:   `{"id": 0, "extra": true, "password": "x"}`,
+			want: []model.Violation{
+				{Pointer: "/name", Keyword: "required", Expected: "property name", Actual: "missing"},
+				{Pointer: "/extra", Keyword: "additionalProperties", Expected: "no undeclared properties", Actual: "property extra"},
+				{Pointer: "/id", Keyword: "minimum", Expected: ">= 1", Actual: "0"},
+				{Pointer: "/password", Keyword: "writeOnly", Expected: "absent from responses", Actual: `"x"`},
+			},
+		},
+		{
+			name:   "String and array constraints",
+			schema: pet,
+			body:   `{"id": 1, "name": "", "status": "lost", "tags": ["a", "a", "b"], "email": "nope"}`,
+			want: []model.Violation{
+				{Pointer: "/email", Keyword: "format", Expected: "email", Actual: `"nope"`},
+				{Pointer: "/name", Keyword: "minLength", Expected: "at least 1 characters", Actual: "0 characters"},
+				{Pointer: "/status", Keyword: "enum", Expected: `one of ["available","sold"]`, Actual: `"lost"`},
+				{Pointer: "/tags", Keyword: "maxItems", Expected: "at most 2 items", Actual: "3 items"},
+				{Pointer: "/tags/1", Keyword: "uniqueItems", Expected: "unique items", Actual: "duplicate of item 0"},
+			},
+		},
+		{
+			name:   "Recursive reference",
+			schema: pet,
+			body:   `{"id": 1, "name": "Rex", "parent": {"id": 2}}`,
+			want: []model.Violation{
+				{Pointer: "/parent/name", Keyword: "required", Expected: "property name", Actual: "missing"},
+			},
+		},
+		{
+			name:   "Nullable",
+			schema: domain.Schema{Type: "string", Types: []string{"string", "null"}},
+			body:   `null`,
+		},
+		{
+			name:   "Null not allowed",
+			schema: domain.Schema{Type: "string"},
+			body:   `null`,
+			want:   []model.Violation{{Keyword: "type", Expected: "string", Actual: "null"}},
+		},
+		{
+			name: "oneOf with several matches",
+			schema: domain.Schema{OneOf: []domain.Schema{
+				{Type: "number"},
+				{Type: "integer"},
+			}},
+			body: `3`,
+			want: []model.Violation{{Keyword: "oneOf", Expected: "exactly one matching schema", Actual: "2 matching schemas"}},
+		},
+		{
+			name: "Discriminated oneOf",
+			schema: domain.Schema{
+				OneOf: []domain.Schema{
+					{Ref: "#/components/schemas/Cat", Type: "object", Required: []string{"meows"}},
+					{Ref: "#/components/schemas/Dog", Type: "object", Required: []string{"barks"}},
+				},
+				Discriminator: &domain.Discriminator{PropertyName: "kind"},
+			},
+			body: `{"kind": "Dog", "meows": true}`,
+			want: []model.Violation{{Pointer: "/barks", Keyword: "required", Expected: "property barks", Actual: "missing"}},
+		},
+		{
+			name:   "anyOf and not",
+			schema: domain.Schema{AnyOf: []domain.Schema{{Type: "string"}, {Type: "boolean"}}, Not: &domain.Schema{Enum: []interface{}{float64(4)}}},
+			body:   `4`,
+			want: []model.Violation{
+				{Keyword: "anyOf", Expected: "at least one matching schema", Actual: "none"},
+				{Keyword: "not", Expected: "value not matching the schema", Actual: "4"},
+			},
+		},
+		{
+			name:   "Recursion through $defs",
+			schema: tree,
+			body:   `{"value": 1, "children": [{"value": 2, "children": [{"value": "three"}]}]}`,
+			want:   []model.Violation{{Pointer: "/children/0/children/0/value", Keyword: "type", Expected: "integer", Actual: "string"}},
+		},
+		{
+			name:   "Properties evaluated by allOf and the matching oneOf",
+			schema: sealed,
+			body:   `{"id": 1, "name": "Rex", "dog": true}`,
+		},
+		{
+			name:   "Unevaluated properties",
+			schema: sealed,
+			body:   `{"id": 1, "dog": true, "colour": "brown"}`,
+			want:   []model.Violation{{Pointer: "/colour", Keyword: "unevaluatedProperties", Expected: "no unevaluated properties", Actual: "property colour"}},
+		},
+		{
+			name:   "Unevaluated properties with a schema",
+			schema: domain.Schema{Type: "object", UnevaluatedProperties: &domain.Schema{Type: "string"}},
+			body:   `{"a": "x", "b": 2}`,
+			want:   []model.Violation{{Pointer: "/b", Keyword: "type", Expected: "string", Actual: "number"}},
+		},
+		{
+			name:   "Exclusive bounds and multipleOf",
+			schema: domain.Schema{Type: "number", Minimum: floatPtr(0), ExclusiveMinimum: true, MultipleOf: floatPtr(0.5)},
+			body:   `0`,
+			want:   []model.Violation{{Keyword: "exclusiveMinimum", Expected: "> 0", Actual: "0"}},
+		},
+	}
+
+	v := NewValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value interface{}
+			assert.NoError(t, json.Unmarshal([]byte(tt.body), &value))
+			assert.Equal(t, tt.want, v.Validate(spec, tt.schema, value))
+		})
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	operation := &domain.Operation{
+		Responses: map[string]domain.Response{
+			"200": {
+				Content: map[string]domain.MediaType{
+					"application/json": {Schema: domain.Schema{Type: "object", Required: []string{"id"}}},
+					"text/*":           {Schema: domain.Schema{Type: "string"}},
+				},
+				Headers: map[string]domain.Header{
+					"X-Rate-Limit": {Required: true, Schema: domain.Schema{Type: "integer", Maximum: floatPtr(100)}},
+					"X-Tags":       {Schema: domain.Schema{Type: "array", Items: &domain.Schema{Type: "string", Enum: []interface{}{"a", "b"}}}},
+					"Content-Type": {Required: true, Schema: domain.Schema{Type: "string"}},
+				},
+			},
+			"204": {},
+			"3XX": {Content: map[string]domain.MediaType{"application/json": {Schema: domain.Schema{Type: "object"}}}},
+			"404": {Content: map[string]domain.MediaType{"application/problem+json": {}}},
+		},
+	}
+	jsonHeader := func(extra ...string) http.Header {
+		header := http.Header{"Content-Type": {"application/json; charset=utf-8"}, "X-Rate-Limit": {"10"}}
+		for i := 0; i+1 < len(extra); i += 2 {
+			header[extra[i]] = []string{extra[i+1]}
+		}
+		return header
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		code   int
+		status string
+		header http.Header
+		body   string
+		want   []model.Violation
+	}{
+		{
+			name:   "Valid JSON response",
+			status: "200",
+			header: jsonHeader("X-Tags", "a, b"),
+			body:   `{"id": 1}`,
+		},
+		{
+			name:   "Body violation",
+			status: "200",
+			header: jsonHeader(),
+			body:   `{}`,
+			want:   []model.Violation{{Pointer: "/id", Keyword: "required", Expected: "property id", Actual: "missing"}},
+		},
+		{
+			name:   "Malformed JSON",
+			status: "200",
+			header: jsonHeader(),
+			body:   `{`,
+			want:   []model.Violation{{Keyword: "content", Expected: "valid JSON", Actual: "unexpected end of JSON input"}},
+		},
+		{
+			name:   "Non-JSON bodies are not checked",
+			status: "200",
+			header: http.Header{"Content-Type": {"text/plain"}, "X-Rate-Limit": {"1"}},
+			body:   `hello`,
+		},
+		{
+			name:   "Undocumented Content-Type",
+			status: "200",
+			header: http.Header{"Content-Type": {"application/xml"}, "X-Rate-Limit": {"1"}},
+			body:   `<pet/>`,
+			want:   []model.Violation{{Header: "Content-Type", Keyword: "content", Expected: "one of application/json, text/*", Actual: "application/xml"}},
+		},
+		{
+			name:   "Missing Content-Type",
+			status: "404",
+			header: http.Header{},
+			body:   `{}`,
+			want:   []model.Violation{{Header: "Content-Type", Keyword: "content", Expected: "one of application/problem+json", Actual: "none"}},
+		},
+		{
+			name:   "Empty body without Content-Type",
+			status: "404",
+			header: http.Header{},
+		},
+		{
+			name:   "Header violations",
+			status: "200",
+			header: http.Header{"Content-Type": {"application/json"}, "X-Tags": {"a,c"}},
+			body:   `{"id": 1}`,
+			want: []model.Violation{
+				{Header: "X-Rate-Limit", Keyword: "required", Expected: "header X-Rate-Limit", Actual: "missing"},
+				{Header: "X-Tags", Pointer: "/1", Keyword: "enum", Expected: `one of ["a","b"]`, Actual: `"c"`},
+			},
+		},
+		{
+			name:   "Header of the wrong type",
+			status: "200",
+			header: jsonHeader("X-Rate-Limit", "lots"),
+			body:   `{"id": 1}`,
+			want:   []model.Violation{{Header: "X-Rate-Limit", Keyword: "type", Expected: "integer", Actual: "string"}},
+		},
+		{
+			name:   "Header out of range",
+			status: "200",
+			header: jsonHeader("X-Rate-Limit", "500"),
+			body:   `{"id": 1}`,
+			want:   []model.Violation{{Header: "X-Rate-Limit", Keyword: "maximum", Expected: "<= 100", Actual: "500"}},
+		},
+		{
+			name:   "No content documented",
+			status: "204",
+			header: http.Header{"Content-Type": {"text/html"}},
+			body:   `ignored`,
+		},
+		{
+			name:   "Undocumented status",
+			status: "500",
+			header: jsonHeader(),
+			body:   `{`,
+		},
+		{
+			name:   "HEAD response with a JSON Content-Type",
+			method: "HEAD",
+			status: "200",
+			header: jsonHeader(),
+		},
+		{
+			name:   "HEAD response headers are still checked",
+			method: "HEAD",
+			status: "200",
+			header: http.Header{"Content-Type": {"application/json"}},
+			want:   []model.Violation{{Header: "X-Rate-Limit", Keyword: "required", Expected: "header X-Rate-Limit", Actual: "missing"}},
+		},
+		{
+			name:   "Not Modified with a JSON Content-Type",
+			code:   304,
+			status: "3XX",
+			header: jsonHeader(),
+		},
+	}
+
+	v := NewValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method, code := tt.method, tt.code
+			if method == "" {
+				method = "GET"
+			}
+			if code == 0 {
+				code, _ = strconv.Atoi(tt.status)
+			}
+			assert.Equal(t, tt.want, v.ValidateResponse(nil, operation, method, code, tt.status, tt.header, []byte(tt.body)))
+		})
+	}
+}
+
+func TestCheckDrift(t *testing.T) {
+	pet := domain.Schema{
+		Type:     "object",
+		Required: []string{"name"},
+		Properties: map[string]domain.Schema{
+			"name":   {Type: "string"},
+			"status": {Type: "string", Enum: []interface{}{"available", "sold"}},
+			"owner": {AllOf: []domain.Schema{
+				{Type: "object", Properties: map[string]domain.Schema{"id": {Type: "integer"}}},
+				{Properties: map[string]domain.Schema{"email": {Type: "string"}}},
+			}},
+			"extras": {Type: "object"},
+			"labels": {Type: "object", AdditionalProperties: &domain.Schema{Type: "string"}},
+		},
+	}
+	operation := &domain.Operation{Responses: map[string]domain.Response{
+		"200": {Content: map[string]domain.MediaType{
+			"application/json": {Schema: domain.Schema{Type: "array", Items: &pet}},
+		}},
+	}}
+	header := http.Header{"Content-Type": {"application/json"}}
+
+	body := `[{
+		"name": "Rex",
+		"status": "adopted",
+		"owner": {"id": 1, "email": "a@example.com", "phone": "555"},
+		"extras": {"anything": true},
+		"labels": {"colour": "brown"},
+		"age": 3
+	}, {"status": 1}]`
+
+	v := NewValidator()
+	violations, drift := v.CheckDrift(nil, operation, "GET", 200, "200", header, []byte(body))
+	assert.Equal(t, []model.Violation{
+		{Pointer: "/1/name", Keyword: "required", Expected: "property name", Actual: "missing"},
+		{Pointer: "/1/status", Keyword: "type", Expected: "string", Actual: "number"},
+	}, violations)
+	assert.Equal(t, []model.Violation{
+		{Pointer: "/0/status", Keyword: "enum", Expected: `one of ["available","sold"]`, Actual: `"adopted"`},
+		{Pointer: "/0/age", Keyword: "additionalProperties", Expected: "declared properties only", Actual: "property age"},
+		{Pointer: "/0/owner/phone", Keyword: "additionalProperties", Expected: "declared properties only", Actual: "property phone"},
+	}, drift)
+
+	// Without drift mode the enum mismatch is a violation
+	violations = v.ValidateResponse(nil, operation, "GET", 200, "200", header, []byte(body))
+	assert.Len(t, violations, 3)
+	assert.Equal(t, "enum", violations[0].Keyword)
+}
+
+func TestCheckDriftRecursive(t *testing.T) {
+	// The recursive reference names the enclosing schema it was inlined
+	// from, as it does for schemas in other files
+	nodeRef := "./schemas/node.yaml#/Node"
+	node := domain.Schema{
+		Ref:  nodeRef,
+		Type: "object",
+		Properties: map[string]domain.Schema{
+			"id":   {Type: "integer"},
+			"next": {Ref: nodeRef},
+		},
+	}
+	operation := &domain.Operation{Responses: map[string]domain.Response{
+		"200": {Content: map[string]domain.MediaType{"application/json": {Schema: node}}},
+	}}
+	header := http.Header{"Content-Type": {"application/json"}}
+
+	violations, drift := NewValidator().CheckDrift(nil, operation, "GET", 200, "200", header, []byte(`{"id": 1, "next": {"id": "two", "next": {"id": 3, "extra": true}}}`))
+	assert.Equal(t, []model.Violation{{Pointer: "/next/id", Keyword: "type", Expected: "integer", Actual: "string"}}, violations)
+	assert.Equal(t, []model.Violation{{Pointer: "/next/next/extra", Keyword: "additionalProperties", Expected: "declared properties only", Actual: "property extra"}}, drift)
+}
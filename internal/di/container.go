@@ -2,22 +2,30 @@ package di
 
 import (
 	"github.com/BarneyRubble12/specdrill/internal/core/executor"
+	"github.com/BarneyRubble12/specdrill/internal/core/fuzzer"
+	"github.com/BarneyRubble12/specdrill/internal/core/generator"
 	"github.com/BarneyRubble12/specdrill/internal/core/parser"
 )
 
 // Container holds all the application dependencies
 type Container struct {
-	Parser   *parser.Parser
-	Executor *executor.Executor
+	Parser    *parser.Parser
+	Generator *generator.Generator
+	Executor  *executor.Executor
+	Fuzzer    *fuzzer.Fuzzer
 }
 
 // NewContainer creates a new application container
 func NewContainer(
 	parser *parser.Parser,
+	generator *generator.Generator,
 	executor *executor.Executor,
+	fuzzer *fuzzer.Fuzzer,
 ) *Container {
 	return &Container{
-		Parser:   parser,
-		Executor: executor,
+		Parser:    parser,
+		Generator: generator,
+		Executor:  executor,
+		Fuzzer:    fuzzer,
 	}
 }
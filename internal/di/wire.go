@@ -5,18 +5,24 @@ package di
 
 import (
 	"github.com/BarneyRubble12/specdrill/internal/core/executor"
+	"github.com/BarneyRubble12/specdrill/internal/core/fuzzer"
+	"github.com/BarneyRubble12/specdrill/internal/core/generator"
 	"github.com/BarneyRubble12/specdrill/internal/core/parser"
+	"github.com/BarneyRubble12/specdrill/internal/core/validator"
 	"github.com/google/wire"
 )
 
 // ProviderSet is a Wire provider set for the application
 var ProviderSet = wire.NewSet(
 	parser.NewParser,
+	generator.NewGenerator,
+	validator.NewValidator,
 	executor.NewExecutor,
+	fuzzer.NewFuzzer,
 )
 
 // InitializeContainer creates a new application container with all dependencies
-func InitializeContainer() (*Container, error) {
+func InitializeContainer(parserOptions parser.Options, generatorOptions generator.Options, executorOptions executor.Options) (*Container, error) {
 	wire.Build(
 		ProviderSet,
 		NewContainer,
@@ -8,21 +8,27 @@ package di
 
 import (
 	"github.com/BarneyRubble12/specdrill/internal/core/executor"
+	"github.com/BarneyRubble12/specdrill/internal/core/fuzzer"
+	"github.com/BarneyRubble12/specdrill/internal/core/generator"
 	"github.com/BarneyRubble12/specdrill/internal/core/parser"
+	"github.com/BarneyRubble12/specdrill/internal/core/validator"
 	"github.com/google/wire"
 )
 
 // Injectors from wire.go:
 
 // InitializeContainer creates a new application container with all dependencies
-func InitializeContainer() (*Container, error) {
-	parserParser := parser.NewParser()
-	executorExecutor := executor.NewExecutor()
-	container := NewContainer(parserParser, executorExecutor)
+func InitializeContainer(parserOptions parser.Options, generatorOptions generator.Options, executorOptions executor.Options) (*Container, error) {
+	parserParser := parser.NewParser(parserOptions)
+	generatorGenerator := generator.NewGenerator(generatorOptions)
+	validatorValidator := validator.NewValidator()
+	executorExecutor := executor.NewExecutor(generatorGenerator, validatorValidator, executorOptions)
+	fuzzerFuzzer := fuzzer.NewFuzzer(generatorGenerator, executorExecutor)
+	container := NewContainer(parserParser, generatorGenerator, executorExecutor, fuzzerFuzzer)
 	return container, nil
 }
 
 // wire.go:
 
 // ProviderSet is a Wire provider set for the application
-var ProviderSet = wire.NewSet(parser.NewParser, executor.NewExecutor)
+var ProviderSet = wire.NewSet(parser.NewParser, generator.NewGenerator, validator.NewValidator, executor.NewExecutor, fuzzer.NewFuzzer)